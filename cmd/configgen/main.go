@@ -0,0 +1,79 @@
+// Command configgen generates the JSON Schema, the example YAML configuration file and the example environment file
+// of the service configuration from internal.Configuration, so they never drift from the code.
+//
+// Run it with go generate ./... or task generate.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/adroit-group/gote/internal"
+	"github.com/adroit-group/gote/pkg/config"
+)
+
+const header = "# Code generated by configgen. DO NOT EDIT.\n"
+
+func main() {
+	dir := flag.String("dir", "configs", "Directory to write the generated files to")
+	name := flag.String("name", "app", "Base name of the generated files")
+	flag.Parse()
+
+	if err := generate(*dir, *name); err != nil {
+		slog.Error("failed to generate the configuration files", "error", err)
+		os.Exit(1)
+	}
+}
+
+func generate(dir, name string) error {
+	configs, err := config.FromStruct[internal.Configuration]()
+	if err != nil {
+		return err
+	}
+
+	schemaFile := name + ".schema.json"
+
+	s := config.JSONSchema(configs)
+	s.Title = "Configuration of the " + name + " service"
+
+	schema, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	var example bytes.Buffer
+
+	example.WriteString(header)
+	fmt.Fprintf(&example, "# yaml-language-server: $schema=%s\n\n", schemaFile)
+
+	if err := config.WriteExampleYAML(&example, configs); err != nil {
+		return err
+	}
+
+	var env bytes.Buffer
+
+	env.WriteString(header + "\n")
+
+	if err := config.WriteExampleEnv(&env, configs); err != nil {
+		return err
+	}
+
+	files := map[string][]byte{
+		schemaFile:            append(schema, '\n'),
+		name + ".yaml":        example.Bytes(),
+		name + ".env.example": env.Bytes(),
+	}
+
+	for file, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, file), content, 0o644); err != nil { //nolint:gosec // the generated files are not sensitive
+			return err
+		}
+	}
+
+	return nil
+}
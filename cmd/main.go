@@ -2,42 +2,179 @@
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"net"
 	"net/http"
 	"os"
+	"strconv"
 
 	"github.com/adroit-group/gote/internal"
 	"github.com/adroit-group/gote/internal/httpserver"
 	"github.com/adroit-group/gote/pkg/config"
+	"github.com/adroit-group/gote/pkg/httphandlers"
+	"github.com/adroit-group/gote/pkg/httpmiddleware"
+	"github.com/adroit-group/gote/pkg/httputils"
 	"github.com/adroit-group/gote/pkg/infra"
 	"github.com/adroit-group/gote/pkg/logger"
 	"github.com/go-playground/validator/v10"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
 func main() {
-	logger.SetupSlog("template", os.Stdout)
-	config.AutoConfigure(internal.Configuration, viper.GetViper())
+	// The bootstrap logger logs the loading of the configuration, it is replaced by the configured one
+	logger.SetupSlog("", os.Stdout)
+
+	configs, err := config.FromStruct[internal.Configuration]()
+	if err != nil {
+		slog.Error("failed to load configuration", "error", err)
+		os.Exit(1)
+	}
+
+	config.RegisterFlags(configs, pflag.CommandLine)
+	printConfig := pflag.Bool("print-config", false, "Print the resolved configuration and exit")
+	pflag.Parse()
+
+	validate := httputils.NewValidator()
+
+	if *printConfig {
+		if err := printConfiguration(configs, validate); err != nil {
+			slog.Error("invalid configuration", "error", err)
+			os.Exit(1)
+		}
+
+		return
+	}
+
+	conf, err := config.New(configs, config.WithFlags(pflag.CommandLine))
+	if err != nil {
+		slog.Error("failed to load configuration", "error", err)
+		os.Exit(1)
+	}
+
+	cfg, err := config.Decode[internal.Configuration](conf, validate)
+	if err != nil {
+		slog.Error("failed to load configuration", "error", err)
+		os.Exit(1)
+	}
+
+	logLevel := &slog.LevelVar{}
+	if err := logLevel.UnmarshalText([]byte(cfg.Log.Level)); err != nil {
+		slog.Error("failed to load configuration", "error", err)
+		os.Exit(1)
+	}
+
+	_, err = logger.Setup(os.Stdout, logger.Options{
+		Service:   cfg.ServiceName,
+		Level:     logLevel,
+		Format:    logger.Format(cfg.Log.Format),
+		AddSource: cfg.Log.Source,
+	})
+	if err != nil {
+		slog.Error("failed to set up logger", "error", err)
+		os.Exit(1)
+	}
+
+	// Register the checks of the dependencies here, e.g. health.Register("database", httphandlers.CheckerFunc(db.PingContext))
+	health := httphandlers.NewHealthRegistry()
+
+	opts := []httpserver.Option{httpserver.WithHealthRegistry(health), httpserver.WithLogLevel(logLevel)}
+	if cfg.HTTP.ExposeConfig {
+		opts = append(opts, httpserver.WithConfigEndpoint(conf.Inspect))
+	}
+
+	trustedProxies, err := httpmiddleware.ParseTrustedProxies(cfg.HTTP.TrustedProxies)
+	if err != nil {
+		slog.Error("failed to load configuration", "error", err)
+		os.Exit(1)
+	}
+
+	opts = append(opts, httpserver.WithMiddlewares(httpmiddleware.Default(httpmiddleware.Config{
+		RequestTimeout: cfg.HTTP.RequestTimeout,
+		MaxBodyBytes:   cfg.HTTP.MaxBodyBytes,
+		TrustedProxies: trustedProxies,
+	})...))
+
+	if !cfg.Admin.Enabled {
+		opts = append(opts, httpserver.WithPublicOperationalEndpoints())
+	}
+
+	if cfg.Admin.Profiling {
+		opts = append(opts, httpserver.WithProfiling())
+	}
+
+	opts = append(opts, httpserver.WithMetricsErrorHandler(func(err error) {
+		slog.Error("failed to create the HTTP handler", "error", err)
+		os.Exit(1)
+	}))
 
-	validate := validator.New()
 	ctx := context.Background()
-	h := httpserver.NewServerHandler(validate)
+	h := httpserver.NewServerHandler(validate, opts...)
 
-	h.RegisterRoutes(viper.GetString(internal.ConfigHTTPBasePath))
+	h.RegisterRoutes(cfg.HTTP.BasePath)
 
 	srv := &http.Server{
-		Addr:              net.JoinHostPort("", viper.GetString(internal.ConfigHTTPPort)),
+		Addr:              net.JoinHostPort("", strconv.Itoa(cfg.HTTP.Port)),
 		Handler:           h,
-		ReadTimeout:       viper.GetDuration(internal.ConfigHTTPReadTimeout),
-		ReadHeaderTimeout: viper.GetDuration(internal.ConfigHTTPReadHeaderTimeout),
-		WriteTimeout:      viper.GetDuration(internal.ConfigHTTPWriteTimeout),
-		IdleTimeout:       viper.GetDuration(internal.ConfigHTTPIdleTimeout),
+		ReadTimeout:       cfg.HTTP.ReadTimeout,
+		ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+		WriteTimeout:      cfg.HTTP.WriteTimeout,
+		IdleTimeout:       cfg.HTTP.IdleTimeout,
+	}
+
+	if cfg.HTTP.TLS.CertFile != "" {
+		srv.TLSConfig, err = infra.NewTLSConfig(infra.TLSConfig{
+			CertFile:     cfg.HTTP.TLS.CertFile,
+			KeyFile:      cfg.HTTP.TLS.KeyFile,
+			MinVersion:   cfg.HTTP.TLS.MinVersion,
+			CipherSuites: cfg.HTTP.TLS.CipherSuites,
+			ClientCAFile: cfg.HTTP.TLS.ClientCAFile,
+		})
+		if err != nil {
+			slog.Error("failed to configure TLS", "error", err)
+			os.Exit(1)
+		}
 	}
 
-	err := infra.RunHTTPServerWithGracefulShutdown(ctx, srv)
+	runOpts := []infra.Option{
+		infra.WithDrain(cfg.HTTP.DrainPeriod, health),
+		infra.WithShutdownTimeout(cfg.HTTP.ShutdownTimeout),
+	}
+
+	if cfg.Admin.Enabled {
+		runOpts = append(runOpts, infra.WithAdminServer(&http.Server{
+			Addr:              net.JoinHostPort("", strconv.Itoa(cfg.Admin.Port)),
+			Handler:           h.AdminHandler(),
+			ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+			IdleTimeout:       cfg.HTTP.IdleTimeout,
+		}))
+	}
+
+	err = infra.RunHTTPServerWithGracefulShutdown(ctx, srv, runOpts...)
 	if err != nil {
 		slog.Error("failed handle server", "error", err)
 		os.Exit(1)
 	}
 }
+
+// printConfiguration prints the resolved configuration to stdout, and validates it afterwards, so the values can be
+// inspected even when they are invalid. The logs are written to stderr, so they are not mixed with the table.
+func printConfiguration(configs []config.Config, validate *validator.Validate) error {
+	logger.SetupSlog("", os.Stderr)
+
+	viperInstance := viper.New()
+	opts := []config.Option{config.WithFlags(pflag.CommandLine)}
+
+	loadErr := config.AutoConfigure(configs, viperInstance, opts...)
+
+	if err := config.WriteResolvedValues(os.Stdout, config.Inspect(configs, viperInstance, opts...)); err != nil {
+		return fmt.Errorf("failed to print configuration: %w", err)
+	}
+
+	if loadErr != nil {
+		return loadErr
+	}
+
+	return config.StructValidator[internal.Configuration](validate)(viperInstance)
+}
@@ -1,50 +1,55 @@
 package internal
 
-import (
-	"time"
+import "time"
 
-	"github.com/adroit-group/gote/pkg/config"
-)
+//go:generate go run ../cmd/configgen -dir ../configs
 
-const (
-	ConfigHTTPBasePath          = "base_path"
-	ConfigHTTPPort              = "http_port"
-	ConfigHTTPReadTimeout       = "http_read_timeout"
-	ConfigHTTPReadHeaderTimeout = "http_read_header_timeout"
-	ConfigHTTPWriteTimeout      = "http_write_timeout"
-	ConfigHTTPIdleTimeout       = "http_idle_timeout"
-)
+// Configuration holds every configuration option of the service.
+//
+// The options are registered from the struct tags, see config.Load for the supported tags.
+type Configuration struct {
+	ServiceName string             `config:"service_name" env:"SERVICE_NAME" default:"template" validate:"required" description:"Name of the service, logged with every log line"`
+	Log         LogConfiguration   `config:"log"`
+	HTTP        HTTPConfiguration  `config:"http"`
+	Admin       AdminConfiguration `config:"admin"`
+}
+
+// LogConfiguration holds the configuration of the logger.
+type LogConfiguration struct {
+	Level  string `config:"level" env:"LOG_LEVEL" flag:"log-level" default:"info" allowed:"debug,info,warn,error" description:"Minimum level of the logged records, it can be changed at runtime on the admin server"`
+	Format string `config:"format" env:"LOG_FORMAT" default:"json" allowed:"json,text,console" description:"Format of the log lines, console is meant for the local development"`
+	Source bool   `config:"source" env:"LOG_SOURCE" default:"true" description:"Log the source file and line of the log calls"`
+}
+
+// HTTPConfiguration holds the configuration of the public HTTP server.
+type HTTPConfiguration struct {
+	BasePath          string               `config:"base_path" env:"HTTP_BASE_PATH" flag:"base-path" default:"/api" description:"Base path of every route"`
+	Port              int                  `config:"port" env:"HTTP_PORT" flag:"port" default:"80" validate:"min=1,max=65535" description:"Port of the HTTP server"`
+	ReadTimeout       time.Duration        `config:"read_timeout" default:"15s" validate:"gt=0" description:"Maximum duration for reading the entire request"`
+	ReadHeaderTimeout time.Duration        `config:"read_header_timeout" default:"5s" validate:"gt=0" description:"Maximum duration for reading the request headers"`
+	WriteTimeout      time.Duration        `config:"write_timeout" default:"15s" validate:"gt=0" description:"Maximum duration before timing out writes of the response"`
+	IdleTimeout       time.Duration        `config:"idle_timeout" default:"60s" validate:"gt=0" description:"Maximum amount of time to wait for the next request on keep-alive connections"`
+	DrainPeriod       time.Duration        `config:"drain_period" env:"HTTP_DRAIN_PERIOD" default:"5s" validate:"gte=0" description:"Duration to keep serving requests with a failing readiness probe before the shutdown"`
+	ShutdownTimeout   time.Duration        `config:"shutdown_timeout" env:"HTTP_SHUTDOWN_TIMEOUT" default:"5s" validate:"gt=0" description:"Maximum duration of the graceful shutdown after the drain period"`
+	RequestTimeout    time.Duration        `config:"request_timeout" env:"HTTP_REQUEST_TIMEOUT" default:"10s" validate:"gte=0" description:"Timeout of the requests, 0 disables it"`
+	MaxBodyBytes      int64                `config:"max_body_bytes" env:"HTTP_MAX_BODY_BYTES" default:"1048576" validate:"gte=0" description:"Maximum size of the request bodies in bytes, 0 disables the limit"`
+	TrustedProxies    []string             `config:"trusted_proxies" env:"HTTP_TRUSTED_PROXIES" validate:"dive,cidr|ip" description:"Comma separated IP addresses and CIDR ranges of the proxies trusted to set the X-Forwarded-For and X-Real-Ip headers"`
+	ExposeConfig      bool                 `config:"expose_config" env:"HTTP_EXPOSE_CONFIG" default:"false" description:"Expose the resolved configuration on the /__config__ endpoint"`
+	TLS               HTTPTLSConfiguration `config:"tls"`
+}
+
+// HTTPTLSConfiguration holds the TLS configuration of the public HTTP server, it serves plain HTTP without a certificate.
+type HTTPTLSConfiguration struct {
+	CertFile     string   `config:"cert_file" env:"HTTP_TLS_CERT_FILE" description:"Path of the PEM encoded certificate chain, enables TLS when set"`
+	KeyFile      string   `config:"key_file" env:"HTTP_TLS_KEY_FILE" validate:"required_with=CertFile" description:"Path of the PEM encoded private key of the certificate"`
+	ClientCAFile string   `config:"client_ca_file" env:"HTTP_TLS_CLIENT_CA_FILE" description:"Path of the PEM encoded CA bundle verifying the client certificates, enables mutual TLS when set"`
+	MinVersion   string   `config:"min_version" env:"HTTP_TLS_MIN_VERSION" default:"1.2" allowed:"1.2,1.3" description:"Minimum accepted TLS version"`
+	CipherSuites []string `config:"cipher_suites" env:"HTTP_TLS_CIPHER_SUITES" description:"Comma separated names of the accepted TLS 1.2 cipher suites, the secure defaults of Go if empty"`
+}
 
-var Configuration = []config.Config{
-	{
-		NameInFile:     "http.base_path",
-		EnvironmentVar: "HTTP_BASE_PATH",
-		Key:            ConfigHTTPBasePath,
-		DefaultValue:   "/api",
-	},
-	{
-		NameInFile:   "http.port",
-		Key:          ConfigHTTPPort,
-		DefaultValue: 80,
-	},
-	{
-		NameInFile:   "http.read_timeout",
-		Key:          ConfigHTTPReadTimeout,
-		DefaultValue: 15 * time.Second,
-	},
-	{
-		NameInFile:   "http.read_header_timeout",
-		Key:          ConfigHTTPReadHeaderTimeout,
-		DefaultValue: 15 * time.Second,
-	},
-	{
-		NameInFile:   "http.write_timeout",
-		Key:          "ConfigHTTPWriteTimeout",
-		DefaultValue: 15 * time.Second,
-	},
-	{
-		NameInFile:   "http.idle_timeout",
-		Key:          ConfigHTTPIdleTimeout,
-		DefaultValue: 60 * time.Second,
-	},
+// AdminConfiguration holds the configuration of the admin server, serving the operational endpoints.
+type AdminConfiguration struct {
+	Enabled   bool `config:"enabled" env:"ADMIN_ENABLED" default:"true" description:"Serve the operational endpoints on the admin server instead of the public one"`
+	Port      int  `config:"port" env:"ADMIN_PORT" flag:"admin-port" default:"8081" validate:"min=1,max=65535" description:"Port of the admin server"`
+	Profiling bool `config:"profiling" env:"ADMIN_PROFILING" default:"false" description:"Expose the pprof endpoints on the admin server under /debug/pprof/"`
 }
@@ -0,0 +1,56 @@
+package httpserver
+
+import (
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/adroit-group/gote/internal/version"
+	"github.com/adroit-group/gote/pkg/httphandlers"
+	"github.com/adroit-group/gote/pkg/httpmiddleware"
+	"github.com/adroit-group/gote/pkg/metrics"
+	"github.com/go-chi/chi/v5"
+)
+
+// AdminHandler returns the handler of the admin server, serving the operational endpoints,
+// like the health and version endpoints, from the root, on a port not exposed to the internet.
+//
+// The requests are served with the request ID, the request logger, the access log and the panic recovery
+// of the default middleware stack, but without its request timeout, which would cut the profiles short.
+func (s *ServerHandler) AdminHandler() http.Handler {
+	r := chi.NewRouter()
+	r.Use(
+		httpmiddleware.RequestID,
+		httpmiddleware.RequestLogger(nil),
+		httpmiddleware.AccessLog,
+		httpmiddleware.Recoverer,
+	)
+	s.registerOperationalRoutes(r)
+
+	if s.logLevel != nil {
+		r.Method(http.MethodGet, "/__log_level__", httphandlers.NewLogLevelHandlerFunc(s.logLevel))
+		r.Method(http.MethodPut, "/__log_level__", httphandlers.NewLogLevelHandlerFunc(s.logLevel))
+	}
+
+	if s.profiling {
+		r.HandleFunc("/debug/pprof/*", pprof.Index)
+		r.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		r.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		r.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		r.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	}
+
+	return r
+}
+
+// registerOperationalRoutes registers the endpoints used by the infrastructure, not by the clients of the service.
+func (s *ServerHandler) registerOperationalRoutes(r chi.Router) {
+	r.Get("/__version__", httphandlers.NewVersionHandlerFunc(version.GetVersion))
+	r.Get("/__live__", httphandlers.NewLivenessHandlerFunc(s.health))
+	r.Get("/__ready__", httphandlers.NewReadinessHandlerFunc(s.health))
+	r.Get("/__health__", httphandlers.NewHealthHandlerFunc(s.health))
+	r.Method(http.MethodGet, "/metrics", metrics.Handler(s.metrics))
+
+	if s.configProvider != nil {
+		r.Get("/__config__", httphandlers.NewConfigHandlerFunc(s.configProvider))
+	}
+}
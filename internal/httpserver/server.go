@@ -1,27 +1,108 @@
 package httpserver
 
 import (
+	"fmt"
 	"log/slog"
 	"net/http"
 
-	"github.com/adroit-group/gote/internal/version"
 	"github.com/adroit-group/gote/pkg/httphandlers"
+	"github.com/adroit-group/gote/pkg/httpmiddleware"
 	"github.com/adroit-group/gote/pkg/httputils"
+	"github.com/adroit-group/gote/pkg/metrics"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 type ServerHandler struct {
-	mux     *chi.Mux
-	valdate *validator.Validate
+	mux            *chi.Mux
+	valdate        *validator.Validate
+	configProvider httphandlers.ResolvedConfigProvider
+	health         *httphandlers.HealthRegistry
+	metrics        *prometheus.Registry
+	metricsErr     func(err error)
+	middlewares    []func(http.Handler) http.Handler
+	logLevel       *slog.LevelVar
+	publicOps      bool
+	profiling      bool
 }
 
 var _ httputils.ServerHandler = (*ServerHandler)(nil)
 
+// Option configures a ServerHandler.
+type Option func(*ServerHandler)
+
+// WithConfigEndpoint mounts the /__config__ endpoint, listing the resolved configuration values.
+func WithConfigEndpoint(provider httphandlers.ResolvedConfigProvider) Option {
+	return func(s *ServerHandler) {
+		s.configProvider = provider
+	}
+}
+
+// WithHealthRegistry sets the registry of the checks run by the health endpoints.
+// Without it, the endpoints report the service as up.
+func WithHealthRegistry(registry *httphandlers.HealthRegistry) Option {
+	return func(s *ServerHandler) {
+		s.health = registry
+	}
+}
+
+// WithMetricsRegistry sets the registry of the HTTP metrics served by the /metrics endpoint,
+// so the components can register their own metrics with it. Without it, a new metrics.NewRegistry is used.
+func WithMetricsRegistry(registry *prometheus.Registry) Option {
+	return func(s *ServerHandler) {
+		s.metrics = registry
+	}
+}
+
+// WithMetricsErrorHandler sets the function called when the HTTP metrics cannot be registered with the registry,
+// e.g. it already has other metrics with the same names. The handler is still created, without the HTTP metrics.
+// Without it, the error is logged.
+func WithMetricsErrorHandler(handler func(err error)) Option {
+	return func(s *ServerHandler) {
+		s.metricsErr = handler
+	}
+}
+
+// WithMiddlewares replaces the default middleware stack, httpmiddleware.Default with httpmiddleware.DefaultConfig,
+// used by every route. Append to httpmiddleware.Default to extend the stack, or pass nothing to disable it.
+// The route groups can extend the stack with chi.Router.Use.
+func WithMiddlewares(middlewares ...func(http.Handler) http.Handler) Option {
+	return func(s *ServerHandler) {
+		s.middlewares = middlewares
+	}
+}
+
+// WithLogLevel mounts the /__log_level__ endpoint on the admin handler, to read and change the log level at runtime.
+func WithLogLevel(level *slog.LevelVar) Option {
+	return func(s *ServerHandler) {
+		s.logLevel = level
+	}
+}
+
+// WithPublicOperationalEndpoints mounts the operational endpoints on the public router as well, under the base URL.
+// Use it when the admin server is disabled, otherwise they are only served by AdminHandler.
+func WithPublicOperationalEndpoints() Option {
+	return func(s *ServerHandler) {
+		s.publicOps = true
+	}
+}
+
+// WithProfiling mounts the pprof endpoints under /debug/pprof/ on the admin handler.
+// They are never mounted on the public router.
+func WithProfiling() Option {
+	return func(s *ServerHandler) {
+		s.profiling = true
+	}
+}
+
+// RegisterRoutes registers the routes of the service under the base URL. The handlers are adapted with
+// httputils.Handle, e.g. r.Post("/users", httputils.Handle(s.valdate, s.users.Create, httputils.WithStatus(http.StatusCreated))).
 func (s *ServerHandler) RegisterRoutes(baseURL string) {
 	s.mux.Route(baseURL, func(r chi.Router) {
-		r.Get("/__version__", httphandlers.NewVersionHandlerFunc(version.GetVersion))
-		r.Get("/__health__", httphandlers.HealthHandlerFunc)
+		if s.publicOps {
+			s.registerOperationalRoutes(r)
+		}
 	})
 	slog.Debug("all routes registered", "baseURL", baseURL)
 }
@@ -31,9 +112,38 @@ func (s *ServerHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 }
 
 // NewServerHandler creates a new ServerHandler.
-func NewServerHandler(v *validator.Validate) *ServerHandler {
-	return &ServerHandler{
-		mux:     chi.NewRouter(),
-		valdate: v,
+// If the HTTP metrics cannot be registered, see metrics.NewHTTPMetrics, it reports the error with the handler
+// of WithMetricsErrorHandler, and serves the requests without them.
+func NewServerHandler(v *validator.Validate, opts ...Option) *ServerHandler {
+	s := &ServerHandler{
+		mux:         chi.NewRouter(),
+		valdate:     v,
+		health:      httphandlers.NewHealthRegistry(),
+		middlewares: httpmiddleware.Default(httpmiddleware.DefaultConfig()),
+	}
+
+	for _, opt := range opts {
+		opt(s)
 	}
+
+	if s.metrics == nil {
+		s.metrics = metrics.NewRegistry()
+	}
+
+	if s.metricsErr == nil {
+		s.metricsErr = func(err error) {
+			slog.Error("serving without the HTTP metrics", "error", err)
+		}
+	}
+
+	httpMetrics, err := metrics.NewHTTPMetrics(s.metrics)
+	if err != nil {
+		s.metricsErr(fmt.Errorf("failed to register the HTTP metrics: %w", err))
+	} else {
+		s.mux.Use(httpMetrics.Middleware)
+	}
+
+	s.mux.Use(s.middlewares...)
+
+	return s
 }
@@ -0,0 +1,156 @@
+package httpserver
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/adroit-group/gote/pkg/config"
+	"github.com/adroit-group/gote/pkg/httpmiddleware"
+	"github.com/adroit-group/gote/pkg/httputils"
+	"github.com/adroit-group/gote/pkg/logger"
+	"github.com/adroit-group/gote/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewServerHandlerSharedRegistry(t *testing.T) {
+	logger.SetupSlog("test", io.Discard)
+
+	registry := metrics.NewRegistry()
+
+	failOnMetricsErr := WithMetricsErrorHandler(func(err error) { t.Error(err) })
+	first := NewServerHandler(httputils.NewValidator(), WithMetricsRegistry(registry), failOnMetricsErr)
+	second := NewServerHandler(httputils.NewValidator(), WithMetricsRegistry(registry), failOnMetricsErr)
+
+	for _, h := range []*ServerHandler{first, second} {
+		h.RegisterRoutes("/api")
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/unknown", nil))
+	}
+
+	rec := httptest.NewRecorder()
+	metrics.Handler(registry).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Contains(t, rec.Body.String(), `http_requests_total{method="GET",route="/api/*",status="404"} 2`)
+}
+
+func TestNewServerHandlerConflictingMetrics(t *testing.T) {
+	logger.SetupSlog("test", io.Discard)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(prometheus.NewCounter(prometheus.CounterOpts{Name: "http_requests_total", Help: "Other."}))
+
+	var metricsErr error
+
+	h := NewServerHandler(httputils.NewValidator(), WithMetricsRegistry(registry),
+		WithMetricsErrorHandler(func(err error) { metricsErr = err }))
+	require.Error(t, metricsErr)
+
+	// The requests are still served, without the HTTP metrics
+	h.RegisterRoutes("/api")
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/unknown", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestServerHandlerRoutes(t *testing.T) {
+	logger.SetupSlog("test", io.Discard)
+
+	allOptions := []Option{
+		WithLogLevel(&slog.LevelVar{}),
+		WithProfiling(),
+		WithConfigEndpoint(func() []config.ResolvedValue { return nil }),
+	}
+
+	testCases := []struct {
+		name           string
+		opts           []Option
+		expectedPublic map[string]int
+		expectedAdmin  map[string]int
+	}{
+		{
+			name: "admin server",
+			opts: allOptions,
+			expectedPublic: map[string]int{
+				"/api/__ready__":     http.StatusNotFound,
+				"/api/__config__":    http.StatusNotFound,
+				"/api/metrics":       http.StatusNotFound,
+				"/__ready__":         http.StatusNotFound,
+				"/api/__log_level__": http.StatusNotFound,
+			},
+			expectedAdmin: map[string]int{
+				"/__version__":   http.StatusOK,
+				"/__live__":      http.StatusOK,
+				"/__ready__":     http.StatusOK,
+				"/__health__":    http.StatusOK,
+				"/metrics":       http.StatusOK,
+				"/__config__":    http.StatusOK,
+				"/__log_level__": http.StatusOK,
+				"/debug/pprof/":  http.StatusOK,
+			},
+		},
+		{
+			name: "admin server without optional endpoints",
+			expectedAdmin: map[string]int{
+				"/__ready__":     http.StatusOK,
+				"/__config__":    http.StatusNotFound,
+				"/__log_level__": http.StatusNotFound,
+				"/debug/pprof/":  http.StatusNotFound,
+			},
+		},
+		{
+			name: "public operational endpoints",
+			opts: append([]Option{WithPublicOperationalEndpoints()}, allOptions...),
+			expectedPublic: map[string]int{
+				"/api/__version__":   http.StatusOK,
+				"/api/__live__":      http.StatusOK,
+				"/api/__ready__":     http.StatusOK,
+				"/api/__health__":    http.StatusOK,
+				"/api/metrics":       http.StatusOK,
+				"/api/__config__":    http.StatusOK,
+				"/__ready__":         http.StatusNotFound,
+				"/api/__log_level__": http.StatusNotFound,
+				"/api/debug/pprof/":  http.StatusNotFound,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := NewServerHandler(httputils.NewValidator(), tc.opts...)
+			h.RegisterRoutes("/api")
+
+			for target, expected := range tc.expectedPublic {
+				rec := httptest.NewRecorder()
+				h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+				assert.Equal(t, expected, rec.Code, "public %s", target)
+			}
+
+			admin := h.AdminHandler()
+
+			for target, expected := range tc.expectedAdmin {
+				rec := httptest.NewRecorder()
+				admin.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+				assert.Equal(t, expected, rec.Code, "admin %s", target)
+			}
+		})
+	}
+}
+
+func TestAdminHandlerMiddlewares(t *testing.T) {
+	logger.SetupSlog("test", io.Discard)
+
+	h := NewServerHandler(httputils.NewValidator(),
+		WithConfigEndpoint(func() []config.ResolvedValue { panic("broken provider") }))
+
+	rec := httptest.NewRecorder()
+
+	require.NotPanics(t, func() {
+		h.AdminHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/__config__", nil))
+	})
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get(httpmiddleware.RequestIDHeader))
+}
@@ -1,8 +1,11 @@
 package config
 
 import (
+	"fmt"
 	"log/slog"
+	"os"
 
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
@@ -19,22 +22,71 @@ type Config struct {
 	// EnvironmentVar is the name of the environment variable to bind to the key.
 	// It is optional and can be empty.
 	EnvironmentVar string
+	// Flag is the name of the command-line flag to bind to the key, without the leading dashes.
+	// It is optional and can be empty, see RegisterFlags.
+	Flag string
 	// Key is the viper key used in the configuration.
 	// It is required and cannot be empty.
 	Key ConfigKey
 	// DefaultValue is the default value for the key.
 	// It is optional and can be nil.
 	DefaultValue any
+	// Required marks the option as mandatory, configuring fails if it resolves to an empty value.
+	Required bool
+	// Secret marks the value as sensitive, it is replaced with Redacted wherever it is printed or logged.
+	Secret bool
+	// Deprecated marks the option as deprecated, a warning is logged when it is set.
+	Deprecated bool
+	// ReplacedBy is the key of the option replacing a deprecated one.
+	// It is optional, if set, the value of the deprecated option is copied to the replacement,
+	// unless the replacement is set explicitly.
+	ReplacedBy ConfigKey
+	// Description is a human-readable description of the option.
+	// It is optional and can be empty.
+	Description string
+	// AllowedValues is the list of the values the option can take.
+	// It is optional, if empty, every value is allowed.
+	AllowedValues []any
+}
+
+// Redacted replaces the values of the secret options wherever they are printed or logged.
+const Redacted = "[REDACTED]"
+
+// Redact returns Redacted in place of the value if the option is a secret, otherwise it returns the value as is.
+func (c Config) Redact(value any) any {
+	if c.Secret && value != nil {
+		return Redacted
+	}
+
+	return value
+}
+
+// LogValue implements slog.LogValuer, so the default value of a secret option is never logged.
+func (c Config) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("key", string(c.Key)),
+		slog.Any("default", c.Redact(c.DefaultValue)),
+	)
 }
 
 // registerConfigOptions registers the configuration options with viper.
 // It binds environment variables, registers aliases, and sets default values.
-func registerConfigOptions(configs []Config, viperInstance *viper.Viper) {
+//
+// Every problem is collected and returned as an Error, the remaining options are still registered.
+func registerConfigOptions(configs []Config, viperInstance *viper.Viper) error {
+	var errs []error
+
 	for _, config := range configs {
+		if config.Key == "" {
+			errs = append(errs, &OptionError{Key: ConfigKey(config.NameInFile), Err: ErrMissingKey})
+
+			continue
+		}
+
 		if config.EnvironmentVar != "" {
 			err := viperInstance.BindEnv(string(config.Key), config.EnvironmentVar)
 			if err != nil {
-				slog.Error("failed to bind environment variable", "error", err)
+				errs = append(errs, &OptionError{Key: config.Key, Err: fmt.Errorf("%w %s: %w", ErrBindEnv, config.EnvironmentVar, err)})
 			}
 		}
 
@@ -46,63 +98,178 @@ func registerConfigOptions(configs []Config, viperInstance *viper.Viper) {
 			viperInstance.SetDefault(string(config.Key), config.DefaultValue)
 		}
 	}
+
+	return newError(errs...)
+}
+
+// applyEnvOverrides sets the values of the options from their environment variables.
+func applyEnvOverrides(configs []Config, viperInstance *viper.Viper) {
+	for _, config := range configs {
+		if config.EnvironmentVar != "" && viperInstance.IsSet(config.EnvironmentVar) {
+			viperInstance.Set(string(config.Key), viperInstance.Get(config.EnvironmentVar))
+		}
+	}
 }
 
-// registerConfigFilePaths registers the paths where viper will look for configuration files.
-func registerConfigFilePaths(viperInstance *viper.Viper) {
-	viperInstance.SetConfigType("yaml")
-	viperInstance.SetConfigName("config")
-	viperInstance.AddConfigPath(".")
-	viperInstance.AddConfigPath("/etc/service/")
-	viperInstance.AddConfigPath("$HOME/.config/service/")
+// applyDeprecations warns about the deprecated options that are set,
+// and copies their values to their replacements, unless the replacements are set explicitly.
+func applyDeprecations(configs []Config, viperInstance *viper.Viper, flags *pflag.FlagSet) {
+	for _, config := range configs {
+		if !config.Deprecated || config.Key == "" || !isExplicitlySet(config, viperInstance, flags) {
+			continue
+		}
+
+		slog.Warn("deprecated configuration option is set", "key", config.Key, "replaced_by", config.ReplacedBy)
+
+		if config.ReplacedBy == "" {
+			continue
+		}
+
+		replacement := Config{Key: config.ReplacedBy}
+		for _, c := range configs {
+			if c.Key == config.ReplacedBy {
+				replacement = c
+			}
+		}
+
+		if !isExplicitlySet(replacement, viperInstance, flags) {
+			viperInstance.Set(string(config.ReplacedBy), viperInstance.Get(string(config.Key)))
+		}
+	}
 }
 
-// ConfigureFromEnv configures viper from environment variables.
-func ConfigureFromEnv(configs []Config, viperInstance *viper.Viper) {
-	registerConfigOptions(configs, viperInstance)
+// isEnvSet reports whether the environment variable is set to a non-empty value.
+// Viper ignores the empty environment variables, see viper.AllowEmptyEnv, so they do not set the option either.
+func isEnvSet(name string) bool {
+	return name != "" && os.Getenv(name) != ""
+}
 
-	viperInstance.AutomaticEnv()
+// isExplicitlySet reports whether the option is set from any source other than its default value.
+func isExplicitlySet(config Config, viperInstance *viper.Viper, flags *pflag.FlagSet) bool {
+	if config.Flag != "" && flags != nil && flags.Changed(config.Flag) {
+		return true
+	}
+
+	if isEnvSet(config.EnvironmentVar) {
+		return true
+	}
+
+	return viperInstance.InConfig(string(config.Key))
+}
+
+// checkValues verifies the resolved values of the options:
+// the required ones must not be empty, the values must be convertible to the type of the default value,
+// and they must be one of the allowed values, if there are any.
+func checkValues(configs []Config, viperInstance *viper.Viper) error {
+	var errs []error
 
 	for _, config := range configs {
-		if config.EnvironmentVar != "" && viperInstance.IsSet(config.EnvironmentVar) {
-			viperInstance.Set(string(config.Key), viperInstance.Get(config.EnvironmentVar))
+		if config.Key == "" {
+			continue
+		}
+
+		if err := checkValue(config, viperInstance.Get(string(config.Key))); err != nil {
+			errs = append(errs, &OptionError{Key: config.Key, Err: err})
 		}
 	}
+
+	return newError(errs...)
 }
 
-// ConfigureFromConfigFile configures viper from a configuration file.
+// resolveAndVerify resolves the references, applies the deprecations and checks the resolved values of the options.
+func resolveAndVerify(configs []Config, viperInstance *viper.Viper, o options) error {
+	if err := resolveReferences(configs, viperInstance, o); err != nil {
+		return err
+	}
+
+	applyDeprecations(configs, viperInstance, o.flags)
+
+	return checkValues(configs, viperInstance)
+}
+
+// ConfigureFromEnv configures viper from environment variables.
 //
-// It reads the YAML configuration file from either the current directory or /etc/app or $HOME/.config/app.
+// It returns an Error describing every option that could not be registered or resolved,
+// including the required options without a value and the values not in the allowed values.
+func ConfigureFromEnv(configs []Config, viperInstance *viper.Viper, opts ...Option) error {
+	if err := registerConfigOptions(configs, viperInstance); err != nil {
+		return err
+	}
+
+	viperInstance.AutomaticEnv()
+	applyEnvOverrides(configs, viperInstance)
+
+	return resolveAndVerify(configs, viperInstance, newOptions(opts))
+}
+
+// ConfigureFromConfigFile configures viper from a configuration file and its overlays.
 //
-// If the configuration file is not found, it logs an error.
+// The configuration file is set explicitly with WithConfigFile, the --config flag, the CONFIG_FILE
+// environment variable or viper.SetConfigFile, otherwise it is looked for in the search paths,
+// see WithServiceName, WithConfigPaths and WithFormats.
 // It does not override any values with environment variables.
-func ConfigureFromConfigFile(configs []Config, viperInstance *viper.Viper) {
-	registerConfigOptions(configs, viperInstance)
+//
+// If the configuration file is not found, the returned error wraps ErrConfigFileNotFound,
+// if it cannot be parsed, the returned error wraps ErrMalformedConfigFile,
+// and if its format is not supported, the returned error wraps ErrUnsupportedConfigFormat.
+func ConfigureFromConfigFile(configs []Config, viperInstance *viper.Viper, opts ...Option) error {
+	o := newOptions(opts)
 
-	if err := viperInstance.ReadInConfig(); err != nil {
-		slog.Error("failed to read config file", "error", err)
+	if err := registerConfigOptions(configs, viperInstance); err != nil {
+		return err
 	}
+
+	if _, err := loadConfigFiles(viperInstance, o); err != nil {
+		return newError(err)
+	}
+
+	return resolveAndVerify(configs, viperInstance, o)
 }
 
-// AutoConfigure automatically configures viper from both environment variables and a configuration file.
+// AutoConfigure automatically configures viper from command-line flags, environment variables and a configuration file.
 //
-// It reads the YAML configuration file from either the current directory or /etc/app or $HOME/.config/app.
+// The configuration file is set explicitly with WithConfigFile, the --config flag, the CONFIG_FILE
+// environment variable or viper.SetConfigFile, otherwise the config.yaml file is looked for in the current directory,
+// /etc/service/ and $HOME/.config/service/, see WithServiceName, WithConfigPaths and WithFormats.
+// The overlays set with WithOverlays are merged on top of it in order.
 //
-// It first reads the configuration file and then overrides any values with environment variables.
-func AutoConfigure(configs []Config, viperInstance *viper.Viper) {
-	registerConfigFilePaths(viperInstance)
-	registerConfigOptions(configs, viperInstance)
+// The values are resolved in the following order of precedence: command-line flag (when bound with WithFlags
+// and set on the command line), environment variable, configuration file, default value.
+// References in the resolved values, like file:///run/secrets/db_password or env:OTHER_VAR,
+// are replaced with the values they point to, see WithResolver.
+// A configuration file missing from the search paths is not an error, the other sources are used instead.
+// Any other problem, like a malformed or missing explicit file, a value of the wrong type, a missing required value
+// or a value that is not allowed, is returned as an Error, so the service can refuse to start with a bad configuration.
+func AutoConfigure(configs []Config, viperInstance *viper.Viper, opts ...Option) error {
+	o := newOptions(opts)
+
+	if err := newError(registerConfigOptions(configs, viperInstance), bindFlags(configs, viperInstance, o.flags)); err != nil {
+		return err
+	}
 
 	viperInstance.AutomaticEnv()
 
-	if err := viperInstance.ReadInConfig(); err != nil {
-		slog.Error("failed to read config file", "error", err)
-		return
+	paths, err := resolveConfigFiles(viperInstance, o)
+	if err != nil {
+		return newError(err)
 	}
 
-	for _, config := range configs {
-		if config.EnvironmentVar != "" && viperInstance.IsSet(config.EnvironmentVar) {
-			viperInstance.Set(string(config.Key), viperInstance.Get(config.EnvironmentVar))
-		}
+	if len(paths) == 0 {
+		slog.Info("no config file found, using environment variables and defaults", "paths", searchPaths(o))
+
+		return resolveAndVerify(configs, viperInstance, o)
+	}
+
+	files, err := readConfigFiles(paths)
+	if err != nil {
+		return newError(err)
 	}
+
+	if err := applyConfigFiles(viperInstance, files); err != nil {
+		return newError(err)
+	}
+
+	slog.Info("configuration files loaded", "files", paths)
+
+	return resolveAndVerify(configs, viperInstance, o)
 }
@@ -3,8 +3,8 @@
 import (
 	"os"
 	"path/filepath"
-	"reflect"
 	"testing"
+	"time"
 
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
@@ -131,7 +131,8 @@ func TestRegisterConfigOptions(t *testing.T) {
 				tt.setup()
 			}
 
-			registerConfigOptions(tt.configs, v)
+			err := registerConfigOptions(tt.configs, v)
+			require.NoError(t, err)
 
 			tt.check(t, v)
 
@@ -142,37 +143,6 @@ func TestRegisterConfigOptions(t *testing.T) {
 	}
 }
 
-func TestRegisterConfigFilePaths(t *testing.T) {
-	v := viper.New()
-
-	registerConfigFilePaths(v)
-
-	// Unfortunately Viper doesn't expose getters for its internal state
-	// Since this is a unit test, we can use reflection to access unexported fields
-	viperVal := reflect.ValueOf(v).Elem()
-
-	configTypeField := viperVal.FieldByName("configType")
-	assert.True(t, configTypeField.IsValid())
-	assert.Equal(t, "yaml", configTypeField.String())
-
-	configNameField := viperVal.FieldByName("configName")
-	assert.True(t, configNameField.IsValid())
-	assert.Equal(t, "config", configNameField.String())
-
-	cwd, err := os.Getwd()
-	assert.NoError(t, err)
-
-	home, err := os.UserHomeDir()
-	assert.NoError(t, err)
-
-	searchPathsField := viperVal.FieldByName("configPaths")
-	assert.True(t, searchPathsField.IsValid())
-	assert.Equal(t, 3, searchPathsField.Len())
-	assert.Equal(t, cwd, searchPathsField.Index(0).String())
-	assert.Equal(t, "/etc/service", searchPathsField.Index(1).String())
-	assert.Equal(t, filepath.Join(home, ".config/service"), searchPathsField.Index(2).String())
-}
-
 func TestConfigureFromEnv(t *testing.T) {
 	testCases := []struct {
 		name     string
@@ -214,7 +184,8 @@ func TestConfigureFromEnv(t *testing.T) {
 			}
 
 			v := viper.New()
-			ConfigureFromEnv(tc.configs, v)
+			err := ConfigureFromEnv(tc.configs, v)
+			require.NoError(t, err)
 
 			for key, expected := range tc.expected {
 				require.Equal(t, expected, v.Get(key), "Value for %s should be set from environment", key)
@@ -271,7 +242,8 @@ func TestAutoConfigure(t *testing.T) {
 			// This is a mock approach
 			v.SetConfigType("yaml")
 
-			AutoConfigure(tc.configs, v)
+			err := AutoConfigure(tc.configs, v)
+			require.NoError(t, err)
 
 			for key, expected := range tc.expected {
 				require.Equal(t, expected, v.Get(key), "Value for %s should be set correctly", key)
@@ -279,3 +251,212 @@ func TestAutoConfigure(t *testing.T) {
 		})
 	}
 }
+
+func TestRegisterConfigOptionsErrors(t *testing.T) {
+	v := viper.New()
+
+	err := registerConfigOptions([]Config{
+		{NameInFile: "missing.key", DefaultValue: "value"},
+		{Key: "valid_key", DefaultValue: "valid"},
+	}, v)
+
+	var configErr *Error
+	require.ErrorAs(t, err, &configErr)
+	require.Len(t, configErr.Errors, 1)
+	require.ErrorIs(t, err, ErrMissingKey)
+
+	var optionErr *OptionError
+	require.ErrorAs(t, err, &optionErr)
+	assert.Equal(t, ConfigKey("missing.key"), optionErr.Key)
+
+	// The valid options are still registered.
+	assert.Equal(t, "valid", v.Get("valid_key"))
+}
+
+func TestConfigureFromEnvTypeMismatch(t *testing.T) {
+	t.Setenv("TEST_ENV_PORT", "eighty")
+	t.Setenv("TEST_ENV_TIMEOUT", "soon")
+
+	err := ConfigureFromEnv([]Config{
+		{Key: "port", EnvironmentVar: "TEST_ENV_PORT", DefaultValue: 80},
+		{Key: "timeout", EnvironmentVar: "TEST_ENV_TIMEOUT", DefaultValue: time.Second},
+	}, viper.New())
+
+	var configErr *Error
+	require.ErrorAs(t, err, &configErr)
+	require.Len(t, configErr.Errors, 2)
+	require.ErrorIs(t, err, ErrTypeMismatch)
+	assert.Contains(t, err.Error(), `"port"`)
+	assert.Contains(t, err.Error(), `"timeout"`)
+}
+
+func TestConfigureFromConfigFile(t *testing.T) {
+	testCases := []struct {
+		name        string
+		content     string
+		expectedErr error
+		expected    map[string]interface{}
+	}{
+		{
+			name:     "valid file",
+			content:  "server:\n  port: 8080\n",
+			expected: map[string]interface{}{"server.port": 8080},
+		},
+		{
+			name:        "malformed file",
+			content:     "server:\n  port: [8080\n",
+			expectedErr: ErrMalformedConfigFile,
+		},
+		{
+			name:        "type mismatch",
+			content:     "server:\n  port: eighty\n",
+			expectedErr: ErrTypeMismatch,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tc.content), 0o600))
+
+			v := viper.New()
+			v.SetConfigFile(path)
+
+			err := ConfigureFromConfigFile([]Config{
+				{Key: "server.port", DefaultValue: 80},
+			}, v)
+
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			for key, expected := range tc.expected {
+				require.Equal(t, expected, v.Get(key), "Value for %s should be read from the file", key)
+			}
+		})
+	}
+}
+
+func TestConfigureFromConfigFileNotFound(t *testing.T) {
+	v := viper.New()
+	v.SetConfigFile(filepath.Join(t.TempDir(), "config.yaml"))
+
+	err := ConfigureFromConfigFile([]Config{{Key: "port", DefaultValue: 80}}, v)
+	require.ErrorIs(t, err, ErrConfigFileNotFound)
+	require.NotErrorIs(t, err, ErrMalformedConfigFile)
+}
+
+func TestAutoConfigureMalformedFile(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("port: [80"), 0o600))
+
+	err := AutoConfigure([]Config{{Key: "port", DefaultValue: 80}}, viper.New(), WithConfigPaths(dir))
+	require.ErrorIs(t, err, ErrMalformedConfigFile)
+}
+
+func TestConfigureFromEnvOptionMetadata(t *testing.T) {
+	testCases := []struct {
+		name        string
+		configs     []Config
+		envVars     map[string]string
+		expectedErr error
+		notInError  string
+	}{
+		{
+			name:        "required value missing",
+			configs:     []Config{{Key: "db_password", EnvironmentVar: "TEST_META_PASSWORD", Required: true}},
+			expectedErr: ErrRequired,
+		},
+		{
+			name:    "required value set",
+			configs: []Config{{Key: "db_password", EnvironmentVar: "TEST_META_PASSWORD", Required: true}},
+			envVars: map[string]string{"TEST_META_PASSWORD": "hunter2"},
+		},
+		{
+			name: "allowed value",
+			configs: []Config{
+				{Key: "log_format", EnvironmentVar: "TEST_META_FORMAT", AllowedValues: []any{"json", "text"}},
+			},
+			envVars: map[string]string{"TEST_META_FORMAT": "text"},
+		},
+		{
+			name: "value not allowed",
+			configs: []Config{
+				{Key: "log_format", EnvironmentVar: "TEST_META_FORMAT", AllowedValues: []any{"json", "text"}},
+			},
+			envVars:     map[string]string{"TEST_META_FORMAT": "xml"},
+			expectedErr: ErrValueNotAllowed,
+		},
+		{
+			name: "secret value is redacted",
+			configs: []Config{
+				{Key: "api_key", EnvironmentVar: "TEST_META_API_KEY", Secret: true, AllowedValues: []any{"a", "b"}},
+			},
+			envVars:     map[string]string{"TEST_META_API_KEY": "hunter2"},
+			expectedErr: ErrValueNotAllowed,
+			notInError:  "hunter2",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for k, v := range tc.envVars {
+				t.Setenv(k, v)
+			}
+
+			err := ConfigureFromEnv(tc.configs, viper.New())
+
+			if tc.expectedErr == nil {
+				require.NoError(t, err)
+
+				return
+			}
+
+			require.ErrorIs(t, err, tc.expectedErr)
+
+			if tc.notInError != "" {
+				assert.NotContains(t, err.Error(), tc.notInError)
+				assert.Contains(t, err.Error(), Redacted)
+			}
+		})
+	}
+}
+
+func TestConfigureFromEnvDeprecated(t *testing.T) {
+	configs := []Config{
+		{Key: "port", EnvironmentVar: "TEST_DEPRECATED_PORT", Deprecated: true, ReplacedBy: "http.port"},
+		{Key: "http.port", EnvironmentVar: "TEST_DEPRECATED_HTTP_PORT", DefaultValue: 80},
+	}
+
+	t.Run("value copied to the replacement", func(t *testing.T) {
+		t.Setenv("TEST_DEPRECATED_PORT", "8080")
+
+		v := viper.New()
+		require.NoError(t, ConfigureFromEnv(configs, v))
+		assert.Equal(t, 8080, v.GetInt("http.port"))
+	})
+
+	t.Run("explicit replacement wins", func(t *testing.T) {
+		t.Setenv("TEST_DEPRECATED_PORT", "8080")
+		t.Setenv("TEST_DEPRECATED_HTTP_PORT", "9090")
+
+		v := viper.New()
+		require.NoError(t, ConfigureFromEnv(configs, v))
+		assert.Equal(t, 9090, v.GetInt("http.port"))
+	})
+}
+
+func TestConfigRedact(t *testing.T) {
+	secret := Config{Key: "api_key", Secret: true, DefaultValue: "hunter2"}
+	public := Config{Key: "port", DefaultValue: 80}
+
+	assert.Equal(t, Redacted, secret.Redact("hunter2"))
+	assert.Nil(t, secret.Redact(nil))
+	assert.Equal(t, 80, public.Redact(80))
+	assert.NotContains(t, secret.LogValue().String(), "hunter2")
+	assert.Contains(t, public.LogValue().String(), "80")
+}
@@ -0,0 +1,192 @@
+package config
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+)
+
+// Configuration is a self-contained configuration, wrapping its own viper instance.
+//
+// Unlike the global viper instance, every Configuration is isolated, so parallel tests and multiple components
+// of a binary do not step on each other. Pass it to the handlers and services that need it,
+// instead of reading the values from global state.
+//
+// It is safe for concurrent use, including the reloads of its Watcher.
+type Configuration struct {
+	mu            sync.RWMutex
+	viperInstance *viper.Viper
+	configs       []Config
+	opts          []Option
+}
+
+// New creates a Configuration with its own viper instance, configured with AutoConfigure using the provided options.
+func New(configs []Config, opts ...Option) (*Configuration, error) {
+	c := &Configuration{
+		viperInstance: viper.New(),
+		configs:       configs,
+		opts:          opts,
+	}
+
+	if err := AutoConfigure(configs, c.viperInstance, opts...); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Get returns the resolved value of the key, or nil if it has no value.
+func (c *Configuration) Get(key ConfigKey) any {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.viperInstance.Get(string(key))
+}
+
+// IsSet reports whether the key has a value from any source, including the default value.
+func (c *Configuration) IsSet(key ConfigKey) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.viperInstance.IsSet(string(key))
+}
+
+// GetString returns the value of the key as a string, or the zero value if it cannot be converted.
+func (c *Configuration) GetString(key ConfigKey) string {
+	return cast.ToString(c.Get(key))
+}
+
+// GetBool returns the value of the key as a bool, or the zero value if it cannot be converted.
+func (c *Configuration) GetBool(key ConfigKey) bool {
+	return cast.ToBool(c.Get(key))
+}
+
+// GetInt returns the value of the key as an int, or the zero value if it cannot be converted.
+func (c *Configuration) GetInt(key ConfigKey) int {
+	return cast.ToInt(c.Get(key))
+}
+
+// GetInt64 returns the value of the key as an int64, or the zero value if it cannot be converted.
+func (c *Configuration) GetInt64(key ConfigKey) int64 {
+	return cast.ToInt64(c.Get(key))
+}
+
+// GetFloat64 returns the value of the key as a float64, or the zero value if it cannot be converted.
+func (c *Configuration) GetFloat64(key ConfigKey) float64 {
+	return cast.ToFloat64(c.Get(key))
+}
+
+// GetDuration returns the value of the key as a time.Duration, or the zero value if it cannot be converted.
+func (c *Configuration) GetDuration(key ConfigKey) time.Duration {
+	return cast.ToDuration(c.Get(key))
+}
+
+// GetStringSlice returns the value of the key as a slice of strings, or the zero value if it cannot be converted.
+func (c *Configuration) GetStringSlice(key ConfigKey) []string {
+	return cast.ToStringSlice(c.Get(key))
+}
+
+// Configs returns a copy of the registered configuration options.
+func (c *Configuration) Configs() []Config {
+	return slices.Clone(c.configs)
+}
+
+// Snapshot returns an immutable copy of the resolved values of the registered options.
+// Later reloads do not change the returned snapshot.
+func (c *Configuration) Snapshot() Snapshot {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return Snapshot{values: currentValues(c.configs, c.viperInstance)}
+}
+
+// Inspect returns the effective value and the source of every registered option, with the secret values redacted.
+func (c *Configuration) Inspect() []ResolvedValue {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return Inspect(c.configs, c.viperInstance, c.opts...)
+}
+
+// NewWatcher creates a Watcher reloading the configuration with the options of New,
+// extended with the provided ones, see WithReloadValidator.
+// The reloads hold the lock of the Configuration while they write its viper instance.
+// It returns an error wrapping ErrConfigFileNotFound if there is no configuration file to watch.
+func (c *Configuration) NewWatcher(opts ...Option) (*Watcher, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	allOpts := append(append([]Option{}, c.opts...), opts...)
+
+	return newWatcher(c.configs, c.viperInstance, &c.mu, newOptions(allOpts))
+}
+
+// Decode decodes the resolved values of the configuration into a new T, and validates it with the provided validator,
+// the same way as Load does. If the validator is nil, a new one is created.
+func Decode[T any](c *Configuration, validate *validator.Validate) (*T, error) {
+	_, fields, err := walkStruct(reflect.TypeFor[T]())
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return decodeStruct[T](c.viperInstance, validate, fields)
+}
+
+// Snapshot is an immutable copy of the resolved configuration values at a point in time.
+type Snapshot struct {
+	values map[ConfigKey]any
+}
+
+// Get returns the value of the key, or nil if it has no value.
+func (s Snapshot) Get(key ConfigKey) any {
+	return s.values[key]
+}
+
+// Values returns a copy of every value in the snapshot.
+func (s Snapshot) Values() map[ConfigKey]any {
+	return maps.Clone(s.values)
+}
+
+// GetString returns the value of the key as a string, or the zero value if it cannot be converted.
+func (s Snapshot) GetString(key ConfigKey) string {
+	return cast.ToString(s.Get(key))
+}
+
+// GetBool returns the value of the key as a bool, or the zero value if it cannot be converted.
+func (s Snapshot) GetBool(key ConfigKey) bool {
+	return cast.ToBool(s.Get(key))
+}
+
+// GetInt returns the value of the key as an int, or the zero value if it cannot be converted.
+func (s Snapshot) GetInt(key ConfigKey) int {
+	return cast.ToInt(s.Get(key))
+}
+
+// GetInt64 returns the value of the key as an int64, or the zero value if it cannot be converted.
+func (s Snapshot) GetInt64(key ConfigKey) int64 {
+	return cast.ToInt64(s.Get(key))
+}
+
+// GetFloat64 returns the value of the key as a float64, or the zero value if it cannot be converted.
+func (s Snapshot) GetFloat64(key ConfigKey) float64 {
+	return cast.ToFloat64(s.Get(key))
+}
+
+// GetDuration returns the value of the key as a time.Duration, or the zero value if it cannot be converted.
+func (s Snapshot) GetDuration(key ConfigKey) time.Duration {
+	return cast.ToDuration(s.Get(key))
+}
+
+// GetStringSlice returns the value of the key as a slice of strings, or the zero value if it cannot be converted.
+func (s Snapshot) GetStringSlice(key ConfigKey) []string {
+	return cast.ToStringSlice(s.Get(key))
+}
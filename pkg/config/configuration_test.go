@@ -0,0 +1,155 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewIsolatesInstances(t *testing.T) {
+	configs := []Config{{Key: "http.port", DefaultValue: 80}}
+
+	first, second := t.TempDir(), t.TempDir()
+	writeFiles(t, first, map[string]string{"config.yaml": "http:\n  port: 8080\n"})
+	writeFiles(t, second, map[string]string{"config.yaml": "http:\n  port: 9090\n"})
+
+	a, err := New(configs, WithConfigPaths(first))
+	require.NoError(t, err)
+
+	b, err := New(configs, WithConfigPaths(second))
+	require.NoError(t, err)
+
+	c, err := New(configs, WithConfigPaths(t.TempDir()))
+	require.NoError(t, err)
+
+	assert.Equal(t, 8080, a.GetInt("http.port"))
+	assert.Equal(t, 9090, b.GetInt("http.port"))
+	assert.Equal(t, 80, c.GetInt("http.port"))
+
+	// The registered options cannot be changed through the returned copy
+	a.Configs()[0].Key = "changed"
+	assert.Equal(t, configs, a.Configs())
+}
+
+func TestNewInvalidConfiguration(t *testing.T) {
+	_, err := New([]Config{{Key: "token", Required: true}}, WithConfigPaths(t.TempDir()))
+	require.ErrorIs(t, err, ErrRequired)
+}
+
+func TestConfigurationTypedGetters(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"config.yaml": "name: orders\nport: \"8080\"\nratio: 0.5\ndebug: true\ntimeout: 5s\ntags: [a, b]\n",
+	})
+
+	conf, err := New([]Config{
+		{Key: "name"},
+		{Key: "port", DefaultValue: 80},
+		{Key: "ratio"},
+		{Key: "debug", DefaultValue: false},
+		{Key: "timeout", DefaultValue: time.Second},
+		{Key: "tags"},
+	}, WithConfigPaths(dir))
+	require.NoError(t, err)
+
+	assert.Equal(t, "orders", conf.GetString("name"))
+	assert.Equal(t, 8080, conf.GetInt("port"))
+	assert.Equal(t, int64(8080), conf.GetInt64("port"))
+	assert.InDelta(t, 0.5, conf.GetFloat64("ratio"), 0)
+	assert.True(t, conf.GetBool("debug"))
+	assert.Equal(t, 5*time.Second, conf.GetDuration("timeout"))
+	assert.Equal(t, []string{"a", "b"}, conf.GetStringSlice("tags"))
+	assert.True(t, conf.IsSet("port"))
+	assert.False(t, conf.IsSet("missing"))
+	assert.Empty(t, conf.GetString("missing"))
+}
+
+func TestConfigurationSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"config.yaml": "log:\n  level: info\nrate_limit: 10\n"})
+
+	conf, err := New(watchTestConfigs, WithConfigPaths(dir))
+	require.NoError(t, err)
+
+	snapshot := conf.Snapshot()
+
+	w, err := conf.NewWatcher()
+	require.NoError(t, err)
+
+	writeFiles(t, dir, map[string]string{"config.yaml": "log:\n  level: debug\nrate_limit: 20\n"})
+	require.NoError(t, w.Reload())
+
+	assert.Equal(t, "debug", conf.GetString("log.level"))
+	assert.Equal(t, 20, conf.GetInt("rate_limit"))
+
+	assert.Equal(t, "info", snapshot.GetString("log.level"))
+	assert.Equal(t, 10, snapshot.GetInt("rate_limit"))
+	assert.Equal(t, map[ConfigKey]any{"log.level": "info", "rate_limit": 10}, snapshot.Values())
+}
+
+func TestConfigurationWatcherRejectsInvalidReload(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"config.yaml": "log:\n  level: info\n"})
+
+	conf, err := New(watchTestConfigs, WithConfigPaths(dir))
+	require.NoError(t, err)
+
+	w, err := conf.NewWatcher()
+	require.NoError(t, err)
+
+	writeFiles(t, dir, map[string]string{"config.yaml": "log:\n  level: verbose\n"})
+	require.ErrorIs(t, w.Reload(), ErrValueNotAllowed)
+	assert.Equal(t, "info", conf.GetString("log.level"))
+}
+
+func TestDecode(t *testing.T) {
+	type configuration struct {
+		HTTP struct {
+			Port    int           `config:"port" default:"80" validate:"min=1"`
+			Timeout time.Duration `config:"timeout" default:"15s"`
+		} `config:"http"`
+	}
+
+	configs, err := FromStruct[configuration]()
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"config.yaml": "http:\n  port: 8080\n"})
+
+	conf, err := New(configs, WithConfigPaths(dir))
+	require.NoError(t, err)
+
+	cfg, err := Decode[configuration](conf, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 8080, cfg.HTTP.Port)
+	assert.Equal(t, 15*time.Second, cfg.HTTP.Timeout)
+
+	writeFiles(t, dir, map[string]string{"config.yaml": "http:\n  port: 0\n"})
+
+	conf, err = New(configs, WithConfigFile(filepath.Join(dir, "config.yaml")))
+	require.NoError(t, err)
+
+	_, err = Decode[configuration](conf, nil)
+
+	var invalidValueErr *InvalidValueError
+	require.ErrorAs(t, err, &invalidValueErr)
+	assert.Equal(t, ConfigKey("http.port"), invalidValueErr.Key)
+}
+
+func TestConfigurationInspect(t *testing.T) {
+	t.Setenv("TEST_CONFIGURATION_TOKEN", "hunter2")
+
+	conf, err := New([]Config{
+		{Key: "port", DefaultValue: 80},
+		{Key: "token", EnvironmentVar: "TEST_CONFIGURATION_TOKEN", Secret: true},
+	}, WithConfigPaths(t.TempDir()))
+	require.NoError(t, err)
+
+	assert.Equal(t, []ResolvedValue{
+		{Key: "port", Value: 80, Source: SourceDefault},
+		{Key: "token", Value: Redacted, Source: SourceEnv},
+	}, conf.Inspect())
+}
@@ -0,0 +1,173 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+var (
+	// ErrMissingKey is returned when a configuration option is registered without a key.
+	ErrMissingKey = errors.New("configuration key is missing")
+	// ErrBindEnv is returned when an environment variable cannot be bound to a configuration key.
+	ErrBindEnv = errors.New("failed to bind environment variable")
+	// ErrBindFlag is returned when a command-line flag cannot be bound to a configuration key.
+	ErrBindFlag = errors.New("failed to bind command-line flag")
+	// ErrConfigFileNotFound is returned when no configuration file is found.
+	// AutoConfigure treats it as acceptable and falls back to the environment variables and the default values.
+	ErrConfigFileNotFound = errors.New("configuration file not found")
+	// ErrMalformedConfigFile is returned when the configuration file exists, but it cannot be read or parsed.
+	ErrMalformedConfigFile = errors.New("malformed configuration file")
+	// ErrUnsupportedConfigFormat is returned when a configuration format, or the extension of the configuration file,
+	// is not one of the SupportedFormats.
+	ErrUnsupportedConfigFormat = errors.New("unsupported configuration format")
+	// ErrTypeMismatch is returned when a resolved value cannot be converted to the type of the default value.
+	ErrTypeMismatch = errors.New("type mismatch")
+	// ErrRequired is returned when a required option resolves to an empty value.
+	ErrRequired = errors.New("required value is missing")
+	// ErrUnresolvedReference is returned when a reference in a configuration value cannot be resolved.
+	ErrUnresolvedReference = errors.New("failed to resolve reference")
+	// ErrValueNotAllowed is returned when a resolved value is not one of the allowed values of the option.
+	ErrValueNotAllowed = errors.New("value is not allowed")
+)
+
+// OptionError describes a problem with a single configuration option.
+type OptionError struct {
+	// Key is the configuration key of the option.
+	Key ConfigKey
+	// Err is the underlying error, it wraps one of the sentinel errors of this package.
+	Err error
+}
+
+func (e *OptionError) Error() string {
+	return fmt.Sprintf("%q: %v", e.Key, e.Err)
+}
+
+func (e *OptionError) Unwrap() error {
+	return e.Err
+}
+
+// Error aggregates every problem found while configuring, so all of them can be fixed at once.
+//
+// Use errors.Is with the sentinel errors of this package, or errors.As with OptionError
+// and InvalidValueError to inspect the individual problems.
+type Error struct {
+	Errors []error
+}
+
+func (e *Error) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		messages = append(messages, err.Error())
+	}
+
+	return "invalid configuration: " + strings.Join(messages, "; ")
+}
+
+func (e *Error) Unwrap() []error {
+	return e.Errors
+}
+
+// newError returns an Error aggregating the non-nil errors, or nil if there are none.
+func newError(errs ...error) error {
+	var collected []error
+
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+
+		var configErr *Error
+		if errors.As(err, &configErr) {
+			collected = append(collected, configErr.Errors...)
+
+			continue
+		}
+
+		collected = append(collected, err)
+	}
+
+	if len(collected) == 0 {
+		return nil
+	}
+
+	return &Error{Errors: collected}
+}
+
+// checkValue verifies a single resolved value against the metadata of the option.
+// The returned errors never contain the value of a secret option.
+func checkValue(config Config, value any) error {
+	if config.Required && isEmpty(value) {
+		return ErrRequired
+	}
+
+	if config.DefaultValue != nil {
+		if err := checkType(value, config.DefaultValue); err != nil {
+			return fmt.Errorf("%w: expected %T, got %v", err, config.DefaultValue, config.Redact(value))
+		}
+	}
+
+	if len(config.AllowedValues) > 0 && !isEmpty(value) && !isAllowed(value, config.AllowedValues) {
+		return fmt.Errorf("%w: %v, allowed values: %v", ErrValueNotAllowed, config.Redact(value), config.AllowedValues)
+	}
+
+	return nil
+}
+
+// isEmpty reports whether the value is missing or is the empty string.
+func isEmpty(value any) bool {
+	if value == nil {
+		return true
+	}
+
+	s, ok := value.(string)
+
+	return ok && s == ""
+}
+
+// isAllowed reports whether the value is one of the allowed values.
+// The values are compared by their string representation, since the values read from
+// the environment variables are always strings.
+func isAllowed(value any, allowedValues []any) bool {
+	valueStr := cast.ToString(value)
+
+	for _, allowed := range allowedValues {
+		if cast.ToString(allowed) == valueStr {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkType reports whether the value can be converted to the type of the default value.
+// Default values of any other type are not checked.
+func checkType(value, defaultValue any) error {
+	var err error
+
+	switch defaultValue.(type) {
+	case string:
+		_, err = cast.ToStringE(value)
+	case bool:
+		_, err = cast.ToBoolE(value)
+	case int, int8, int16, int32, int64:
+		_, err = cast.ToInt64E(value)
+	case uint, uint8, uint16, uint32, uint64:
+		_, err = cast.ToUint64E(value)
+	case float32, float64:
+		_, err = cast.ToFloat64E(value)
+	case time.Duration:
+		_, err = cast.ToDurationE(value)
+	case []string:
+		_, err = cast.ToStringSliceE(value)
+	}
+
+	if err != nil {
+		return ErrTypeMismatch
+	}
+
+	return nil
+}
@@ -0,0 +1,249 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cast"
+	"go.yaml.in/yaml/v3"
+)
+
+// exampleIndent is the indentation of the nested keys in the example configuration files.
+const exampleIndent = "  "
+
+// WriteExampleYAML writes an example YAML configuration file with every option commented out with its default value,
+// and its description, environment variable, flag and constraints. The keys of the sections are not commented out,
+// so an option can be set by uncommenting its own line.
+//
+// The file sets nothing until the options are uncommented, so it can be shipped as the configuration file of the
+// service, and the options left alone are still reported with their default source, see Source.
+func WriteExampleYAML(w io.Writer, configs []Config) error {
+	ew := &exampleWriter{w: w}
+	ew.writeYAMLNodes(newExampleTree(configs), 0)
+
+	return ew.err
+}
+
+// WriteExampleEnv writes an example environment file with every option bound to an environment variable
+// set to its default value, and commented with its description and constraints.
+//
+// The options without a default value, and the secrets are written commented out.
+func WriteExampleEnv(w io.Writer, configs []Config) error {
+	ew := &exampleWriter{w: w}
+
+	for _, config := range configs {
+		if config.EnvironmentVar == "" {
+			continue
+		}
+
+		if ew.written {
+			ew.println("")
+		}
+
+		for _, line := range describe(config) {
+			ew.println("# " + line)
+		}
+
+		line := config.EnvironmentVar + "=" + envValue(config.DefaultValue)
+		if config.DefaultValue == nil || config.Secret {
+			line = "# " + config.EnvironmentVar + "="
+		}
+
+		ew.println(line)
+	}
+
+	return ew.err
+}
+
+// describe returns the sentences describing the option in the generated files, see describeSources.
+func describe(config Config) []string {
+	var lines []string
+
+	if config.Description != "" {
+		lines = append(lines, sentence(config.Description))
+	}
+
+	if config.Required {
+		lines = append(lines, "Required.")
+	}
+
+	if config.Secret {
+		lines = append(lines, "Secret.")
+	}
+
+	switch {
+	case config.Deprecated && config.ReplacedBy != "":
+		lines = append(lines, fmt.Sprintf("Deprecated, use %s instead.", config.ReplacedBy))
+	case config.Deprecated:
+		lines = append(lines, "Deprecated.")
+	}
+
+	if len(config.AllowedValues) > 0 {
+		allowed := make([]string, 0, len(config.AllowedValues))
+		for _, value := range config.AllowedValues {
+			allowed = append(allowed, cast.ToString(value))
+		}
+
+		lines = append(lines, fmt.Sprintf("Allowed values: %s.", strings.Join(allowed, ", ")))
+	}
+
+	return lines
+}
+
+// describeSources returns the sentences describing the environment variable and the flag of the option.
+func describeSources(config Config) []string {
+	var lines []string
+
+	if config.EnvironmentVar != "" {
+		lines = append(lines, fmt.Sprintf("Environment variable: %s.", config.EnvironmentVar))
+	}
+
+	if config.Flag != "" {
+		lines = append(lines, fmt.Sprintf("Flag: --%s.", config.Flag))
+	}
+
+	return lines
+}
+
+// sentence capitalizes the text and terminates it with a period, if it is not terminated yet.
+func sentence(text string) string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return text
+	}
+
+	text = strings.ToUpper(text[:1]) + text[1:]
+	if !strings.HasSuffix(text, ".") {
+		text += "."
+	}
+
+	return text
+}
+
+// exampleNode is a key of the example configuration file, either an option or a section of nested keys.
+type exampleNode struct {
+	name     string
+	config   *Config
+	children []*exampleNode
+}
+
+// newExampleTree arranges the options into sections by the segments of their keys, keeping the order of the options.
+func newExampleTree(configs []Config) []*exampleNode {
+	root := &exampleNode{}
+
+	for i := range configs {
+		if configs[i].Key == "" {
+			continue
+		}
+
+		node := root
+		for _, segment := range strings.Split(string(configs[i].Key), ".") {
+			node = node.child(segment)
+		}
+
+		node.config = &configs[i]
+	}
+
+	return root.children
+}
+
+// child returns the child node with the name, adding it if it does not exist yet.
+func (n *exampleNode) child(name string) *exampleNode {
+	for _, child := range n.children {
+		if child.name == name {
+			return child
+		}
+	}
+
+	child := &exampleNode{name: name}
+	n.children = append(n.children, child)
+
+	return child
+}
+
+// exampleWriter writes the lines of an example file, keeping the first error.
+type exampleWriter struct {
+	w       io.Writer
+	err     error
+	written bool
+}
+
+func (ew *exampleWriter) println(line string) {
+	if ew.err != nil {
+		return
+	}
+
+	ew.written = true
+	_, ew.err = fmt.Fprintln(ew.w, line)
+}
+
+// writeYAMLNodes writes the nodes at the depth, separating the options and sections with empty lines.
+func (ew *exampleWriter) writeYAMLNodes(nodes []*exampleNode, depth int) {
+	indent := strings.Repeat(exampleIndent, depth)
+
+	for i, node := range nodes {
+		if i > 0 {
+			ew.println("")
+		}
+
+		if node.config == nil {
+			ew.println(indent + node.name + ":")
+			ew.writeYAMLNodes(node.children, depth+1)
+
+			continue
+		}
+
+		for _, line := range append(describe(*node.config), describeSources(*node.config)...) {
+			ew.println(indent + "# " + line)
+		}
+
+		if node.config.DefaultValue == nil || node.config.Secret {
+			ew.println(indent + "# " + node.name + ":")
+
+			continue
+		}
+
+		ew.println(indent + "# " + node.name + ": " + yamlValue(node.config.DefaultValue))
+	}
+}
+
+// yamlValue formats the value as an inline YAML value, using the flow style for the collections.
+func yamlValue(value any) string {
+	var node yaml.Node
+	if err := node.Encode(exampleValue(value)); err != nil {
+		return cast.ToString(value)
+	}
+
+	if node.Kind == yaml.SequenceNode || node.Kind == yaml.MappingNode {
+		node.Style = yaml.FlowStyle
+	}
+
+	out, err := yaml.Marshal(&node)
+	if err != nil {
+		return cast.ToString(value)
+	}
+
+	return strings.TrimSuffix(string(out), "\n")
+}
+
+// envValue formats the value as the value of an environment variable, quoted if needed.
+// The items of the slices are separated by commas, the way the environment variables are split when they are decoded,
+// see Load.
+func envValue(value any) string {
+	value = exampleValue(value)
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		value = strings.Join(cast.ToStringSlice(value), ",")
+	}
+
+	s := cast.ToString(value)
+	if strings.ContainsAny(s, " \t\n\"'#$\\") {
+		return strconv.Quote(s)
+	}
+
+	return s
+}
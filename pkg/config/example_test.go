@@ -0,0 +1,106 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var exampleTestConfigs = []Config{
+	{Key: "http.port", DefaultValue: 80, Description: "port of the HTTP server", EnvironmentVar: "HTTP_PORT", Flag: "port"},
+	{Key: "http.timeout", DefaultValue: 15 * time.Second},
+	{Key: "log.level", DefaultValue: "info", AllowedValues: []any{"debug", "info"}, EnvironmentVar: "LOG_LEVEL"},
+	{Key: "tags", DefaultValue: []string{"a", "b c"}, EnvironmentVar: "TAGS"},
+	{Key: "token", DefaultValue: "hunter2", Secret: true, EnvironmentVar: "TOKEN"},
+	{Key: "name", Required: true},
+}
+
+func TestWriteExampleYAML(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteExampleYAML(&buf, exampleTestConfigs))
+
+	assert.Equal(t, `http:
+  # Port of the HTTP server.
+  # Environment variable: HTTP_PORT.
+  # Flag: --port.
+  # port: 80
+
+  # timeout: 15s
+
+log:
+  # Allowed values: debug, info.
+  # Environment variable: LOG_LEVEL.
+  # level: info
+
+# Environment variable: TAGS.
+# tags: [a, b c]
+
+# Secret.
+# Environment variable: TOKEN.
+# token:
+
+# Required.
+# name:
+`, buf.String())
+
+	// The example sets nothing, so the options keep their default source
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+
+	configs := exampleTestConfigs[:len(exampleTestConfigs)-1] // without the required option
+
+	v := viper.New()
+	require.NoError(t, AutoConfigure(configs, v, WithConfigFile(path)))
+
+	for _, value := range Inspect(configs, v) {
+		assert.NotEqual(t, SourceFile, value.Source, value.Key)
+	}
+
+	// Uncommenting the options resolves them to the default values
+	uncommented := regexp.MustCompile(`(?m)^(\s*)# ([a-z_]+:.*)$`).ReplaceAllString(buf.String(), "$1$2")
+
+	v = viper.New()
+	v.SetConfigType("yaml")
+	require.NoError(t, v.ReadConfig(strings.NewReader(uncommented)))
+	assert.Equal(t, 80, v.GetInt("http.port"))
+	assert.Equal(t, 15*time.Second, v.GetDuration("http.timeout"))
+	assert.Equal(t, []string{"a", "b c"}, v.GetStringSlice("tags"))
+	assert.Nil(t, v.Get("token"))
+}
+
+func TestWriteExampleEnv(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteExampleEnv(&buf, exampleTestConfigs))
+
+	assert.Equal(t, `# Port of the HTTP server.
+HTTP_PORT=80
+
+# Allowed values: debug, info.
+LOG_LEVEL=info
+
+TAGS="a,b c"
+
+# Secret.
+# TOKEN=
+`, buf.String())
+
+	// The slices are decoded from the environment variables the way they are written
+	t.Setenv("TAGS", "a,b c")
+
+	var decoded struct {
+		Tags []string `config:"tags"`
+	}
+
+	v := viper.New()
+	require.NoError(t, v.BindEnv("tags", "TAGS"))
+	require.NoError(t, v.Unmarshal(&decoded, withConfigTag))
+	assert.Equal(t, []string{"a", "b c"}, decoded.Tags)
+}
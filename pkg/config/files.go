@@ -0,0 +1,207 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// DefaultServiceName is the service name used in the configuration search paths, see WithServiceName.
+	DefaultServiceName = "service"
+	// ConfigFileName is the name of the configuration file without the extension.
+	ConfigFileName = "config"
+	// ConfigFileFlag is the name of the command-line flag holding the path of the configuration file.
+	// RegisterFlags defines it on the flag set.
+	ConfigFileFlag = "config"
+	// ConfigFileEnvironmentVar is the name of the environment variable holding the path of the configuration file.
+	ConfigFileEnvironmentVar = "CONFIG_FILE"
+)
+
+// SupportedFormats lists the supported configuration file formats, by their file extensions.
+var SupportedFormats = []string{"yaml", "yml", "toml", "json"}
+
+// configFile is a configuration file read from the disk.
+type configFile struct {
+	path    string
+	content []byte
+}
+
+// searchPaths returns the directories where the configuration file is looked for.
+func searchPaths(o options) []string {
+	if len(o.configPaths) > 0 {
+		return o.configPaths
+	}
+
+	serviceName := o.serviceName
+	if serviceName == "" {
+		serviceName = DefaultServiceName
+	}
+
+	return []string{
+		".",
+		filepath.Join("/etc", serviceName),
+		filepath.Join("$HOME", ".config", serviceName),
+	}
+}
+
+// formats returns the accepted configuration file formats, in the order of preference.
+func formats(o options) []string {
+	if len(o.formats) > 0 {
+		return o.formats
+	}
+
+	return []string{"yaml"}
+}
+
+// explicitConfigFile returns the path of the configuration file set explicitly, in the order of precedence:
+// WithConfigFile, the --config flag, the CONFIG_FILE environment variable and viper.SetConfigFile.
+func explicitConfigFile(viperInstance *viper.Viper, o options) string {
+	if o.configFile != "" {
+		return o.configFile
+	}
+
+	if o.flags != nil && o.flags.Changed(ConfigFileFlag) {
+		if flag := o.flags.Lookup(ConfigFileFlag); flag != nil {
+			return flag.Value.String()
+		}
+	}
+
+	if path := os.Getenv(ConfigFileEnvironmentVar); path != "" {
+		return path
+	}
+
+	return viperInstance.ConfigFileUsed()
+}
+
+// resolveConfigFiles returns the paths of the base configuration file and its existing overlays, in merge order.
+//
+// If the base file is set explicitly, it must exist, otherwise it is looked for in the search paths,
+// and no paths are returned if it is not found.
+// The overlays are looked for next to the base file, e.g. config.production.yaml next to config.yaml.
+func resolveConfigFiles(viperInstance *viper.Viper, o options) ([]string, error) {
+	for _, format := range formats(o) {
+		if !slices.Contains(SupportedFormats, format) {
+			return nil, fmt.Errorf("%w: %q", ErrUnsupportedConfigFormat, format)
+		}
+	}
+
+	base := explicitConfigFile(viperInstance, o)
+	if base != "" {
+		if _, err := os.Stat(base); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrConfigFileNotFound, err)
+		}
+
+		if format := strings.TrimPrefix(filepath.Ext(base), "."); !slices.Contains(SupportedFormats, format) {
+			return nil, fmt.Errorf("%w: %q of %s", ErrUnsupportedConfigFormat, format, base)
+		}
+	} else {
+		base = findFile(searchPaths(o), ConfigFileName, formats(o))
+		if base == "" {
+			return nil, nil
+		}
+	}
+
+	files := []string{base}
+
+	dir := filepath.Dir(base)
+	name := strings.TrimSuffix(filepath.Base(base), filepath.Ext(base))
+
+	for _, overlay := range o.overlays {
+		if overlay == "" {
+			continue
+		}
+
+		if path := findFile([]string{dir}, name+"."+overlay, formats(o)); path != "" {
+			files = append(files, path)
+		}
+	}
+
+	return files, nil
+}
+
+// findFile returns the first existing file with the given name and any of the formats in the directories.
+func findFile(dirs []string, name string, formats []string) string {
+	for _, dir := range dirs {
+		for _, format := range formats {
+			path := filepath.Join(os.ExpandEnv(dir), name+"."+format)
+
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path
+			}
+		}
+	}
+
+	return ""
+}
+
+// readConfigFiles reads the content of the configuration files.
+func readConfigFiles(paths []string) ([]configFile, error) {
+	files := make([]configFile, 0, len(paths))
+
+	for _, path := range paths {
+		content, err := os.ReadFile(path) //nolint:gosec // reading the configuration file of the service is intended
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %w", ErrConfigFileNotFound, err)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrMalformedConfigFile, err)
+		}
+
+		files = append(files, configFile{path: path, content: content})
+	}
+
+	return files, nil
+}
+
+// applyConfigFiles replaces the configuration of viper with the first file, and merges the rest into it in order.
+func applyConfigFiles(viperInstance *viper.Viper, files []configFile) error {
+	for i, file := range files {
+		viperInstance.SetConfigType(strings.TrimPrefix(filepath.Ext(file.path), "."))
+
+		var err error
+		if i == 0 {
+			err = viperInstance.ReadConfig(bytes.NewReader(file.content))
+		} else {
+			err = viperInstance.MergeConfig(bytes.NewReader(file.content))
+		}
+
+		if err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrMalformedConfigFile, file.path, err)
+		}
+	}
+
+	return nil
+}
+
+// loadConfigFiles resolves, reads and applies the configuration files.
+// It returns the paths of the applied files, or an error wrapping ErrConfigFileNotFound if there are none.
+func loadConfigFiles(viperInstance *viper.Viper, o options) ([]string, error) {
+	paths, err := resolveConfigFiles(viperInstance, o)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("%w in %v", ErrConfigFileNotFound, searchPaths(o))
+	}
+
+	files, err := readConfigFiles(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyConfigFiles(viperInstance, files); err != nil {
+		return nil, err
+	}
+
+	return paths, nil
+}
@@ -0,0 +1,191 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+}
+
+func TestSearchPaths(t *testing.T) {
+	assert.Equal(t, []string{".", "/etc/service", "$HOME/.config/service"}, searchPaths(newOptions(nil)))
+	assert.Equal(t, []string{".", "/etc/orders", "$HOME/.config/orders"},
+		searchPaths(newOptions([]Option{WithServiceName("orders")})))
+	assert.Equal(t, []string{"/opt/orders"},
+		searchPaths(newOptions([]Option{WithServiceName("orders"), WithConfigPaths("/opt/orders")})))
+}
+
+func TestResolveConfigFiles(t *testing.T) {
+	testCases := []struct {
+		name        string
+		files       map[string]string
+		opts        func(dir string) []Option
+		envVars     map[string]string
+		expected    []string
+		expectedErr error
+	}{
+		{
+			name:  "no file found",
+			files: map[string]string{},
+			opts: func(dir string) []Option {
+				return []Option{WithConfigPaths(dir)}
+			},
+			expected: nil,
+		},
+		{
+			name:  "yaml by default",
+			files: map[string]string{"config.yaml": "", "config.json": ""},
+			opts: func(dir string) []Option {
+				return []Option{WithConfigPaths(dir)}
+			},
+			expected: []string{"config.yaml"},
+		},
+		{
+			name:  "formats in order of preference",
+			files: map[string]string{"config.yaml": "", "config.toml": ""},
+			opts: func(dir string) []Option {
+				return []Option{WithConfigPaths(dir), WithFormats("toml", "yaml")}
+			},
+			expected: []string{"config.toml"},
+		},
+		{
+			name:  "unsupported format",
+			files: map[string]string{},
+			opts: func(dir string) []Option {
+				return []Option{WithConfigPaths(dir), WithFormats("ini")}
+			},
+			expectedErr: ErrUnsupportedConfigFormat,
+		},
+		{
+			name:  "unsupported extension of explicit file",
+			files: map[string]string{"config.ini": ""},
+			opts: func(dir string) []Option {
+				return []Option{WithConfigFile(filepath.Join(dir, "config.ini"))}
+			},
+			expectedErr: ErrUnsupportedConfigFormat,
+		},
+		{
+			name:  "overlays in order",
+			files: map[string]string{"config.yaml": "", "config.production.yaml": "", "config.local.yaml": ""},
+			opts: func(dir string) []Option {
+				return []Option{WithConfigPaths(dir), WithOverlays("production", "missing", "local")}
+			},
+			expected: []string{"config.yaml", "config.production.yaml", "config.local.yaml"},
+		},
+		{
+			name:  "explicit file",
+			files: map[string]string{"config.yaml": "", "custom.json": "", "custom.production.json": ""},
+			opts: func(dir string) []Option {
+				return []Option{
+					WithConfigPaths(dir),
+					WithConfigFile(filepath.Join(dir, "custom.json")),
+					WithFormats("json"),
+					WithOverlays("production"),
+				}
+			},
+			expected: []string{"custom.json", "custom.production.json"},
+		},
+		{
+			name:    "explicit file from environment variable",
+			files:   map[string]string{"config.yaml": "", "custom.yaml": ""},
+			envVars: map[string]string{ConfigFileEnvironmentVar: "custom.yaml"},
+			opts: func(dir string) []Option {
+				return []Option{WithConfigPaths(dir)}
+			},
+			expected: []string{"custom.yaml"},
+		},
+		{
+			name:  "missing explicit file",
+			files: map[string]string{"config.yaml": ""},
+			opts: func(dir string) []Option {
+				return []Option{WithConfigPaths(dir), WithConfigFile(filepath.Join(dir, "missing.yaml"))}
+			},
+			expectedErr: ErrConfigFileNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tc.files)
+
+			for k, v := range tc.envVars {
+				// Relative paths in the environment variables are resolved against the test directory
+				t.Setenv(k, filepath.Join(dir, v))
+			}
+
+			paths, err := resolveConfigFiles(viper.New(), newOptions(tc.opts(dir)))
+
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			var expected []string
+			for _, name := range tc.expected {
+				expected = append(expected, filepath.Join(dir, name))
+			}
+
+			assert.Equal(t, expected, paths)
+		})
+	}
+}
+
+func TestAutoConfigureOverlays(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"config.yaml":            "http:\n  port: 80\n  base_path: /api\n",
+		"config.production.toml": "[http]\nport = 8080\n",
+	})
+
+	configs := []Config{
+		{Key: "http.port", DefaultValue: 1},
+		{Key: "http.base_path", DefaultValue: "/"},
+	}
+
+	v := viper.New()
+	err := AutoConfigure(configs, v, WithConfigPaths(dir), WithFormats("yaml", "toml"), WithOverlays("production"))
+	require.NoError(t, err)
+
+	assert.Equal(t, 8080, v.GetInt("http.port"))
+	assert.Equal(t, "/api", v.GetString("http.base_path"))
+}
+
+func TestAutoConfigureConfigFileFlag(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"config.yaml": "port: 80\n",
+		"custom.yaml": "port: 8080\n",
+	})
+
+	configs := []Config{{Key: "port", DefaultValue: 1}}
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	RegisterFlags(configs, fs)
+	require.NoError(t, fs.Parse([]string{"--config", filepath.Join(dir, "custom.yaml")}))
+
+	v := viper.New()
+	require.NoError(t, AutoConfigure(configs, v, WithConfigPaths(dir), WithFlags(fs)))
+	assert.Equal(t, 8080, v.GetInt("port"))
+}
+
+func TestAutoConfigureMissingExplicitFile(t *testing.T) {
+	err := AutoConfigure([]Config{{Key: "port", DefaultValue: 80}}, viper.New(),
+		WithConfigFile(filepath.Join(t.TempDir(), "missing.yaml")))
+	require.ErrorIs(t, err, ErrConfigFileNotFound)
+}
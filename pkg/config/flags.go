@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// RegisterFlags defines a command-line flag on the flag set for every option with a Flag name.
+//
+// The type of the flag is derived from the type of the default value, and the usage is derived from the
+// description and the environment variable of the option, so the --help output of the flag set documents them.
+// The default values of secret options are not shown.
+//
+// It also defines the --config flag holding the path of the configuration file, unless it is already defined.
+func RegisterFlags(configs []Config, flags *pflag.FlagSet) {
+	if flags.Lookup(ConfigFileFlag) == nil {
+		flags.String(ConfigFileFlag, "", fmt.Sprintf("Path of the configuration file (env %s)", ConfigFileEnvironmentVar))
+	}
+
+	for _, config := range configs {
+		if config.Flag == "" {
+			continue
+		}
+
+		usage := config.Description
+		if config.EnvironmentVar != "" {
+			usage += fmt.Sprintf(" (env %s)", config.EnvironmentVar)
+		}
+
+		defaultValue := config.DefaultValue
+		if config.Secret {
+			defaultValue = nil
+		}
+
+		switch def := defaultValue.(type) {
+		case bool:
+			flags.Bool(config.Flag, def, usage)
+		case int:
+			flags.Int(config.Flag, def, usage)
+		case int64:
+			flags.Int64(config.Flag, def, usage)
+		case float64:
+			flags.Float64(config.Flag, def, usage)
+		case time.Duration:
+			flags.Duration(config.Flag, def, usage)
+		case []string:
+			flags.StringSlice(config.Flag, def, usage)
+		case nil:
+			flags.String(config.Flag, "", usage)
+		default:
+			flags.String(config.Flag, cast.ToString(def), usage)
+		}
+	}
+}
+
+// bindFlags binds the flags of the options to their keys.
+// A flag only overrides the other sources when it is set on the command line.
+func bindFlags(configs []Config, viperInstance *viper.Viper, flags *pflag.FlagSet) error {
+	if flags == nil {
+		return nil
+	}
+
+	var errs []error
+
+	for _, config := range configs {
+		if config.Flag == "" || config.Key == "" {
+			continue
+		}
+
+		flag := flags.Lookup(config.Flag)
+		if flag == nil {
+			errs = append(errs, &OptionError{Key: config.Key, Err: fmt.Errorf("%w: --%s is not defined", ErrBindFlag, config.Flag)})
+
+			continue
+		}
+
+		if err := viperInstance.BindPFlag(string(config.Key), flag); err != nil {
+			errs = append(errs, &OptionError{Key: config.Key, Err: fmt.Errorf("%w --%s: %w", ErrBindFlag, config.Flag, err)})
+		}
+	}
+
+	return newError(errs...)
+}
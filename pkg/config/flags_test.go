@@ -0,0 +1,93 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterFlags(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+
+	RegisterFlags([]Config{
+		{Key: "http.port", Flag: "port", DefaultValue: 80, Description: "Port of the HTTP server", EnvironmentVar: "HTTP_PORT"},
+		{Key: "http.timeout", Flag: "timeout", DefaultValue: 15 * time.Second},
+		{Key: "debug", Flag: "debug", DefaultValue: false},
+		{Key: "tags", Flag: "tags", DefaultValue: []string{"a", "b"}},
+		{Key: "name", Flag: "name"},
+		{Key: "token", Flag: "token", DefaultValue: "hunter2", Secret: true},
+		{Key: "no_flag", DefaultValue: "value"},
+	}, fs)
+
+	testCases := []struct {
+		flag         string
+		expectedType string
+		expectedDef  string
+	}{
+		{flag: "port", expectedType: "int", expectedDef: "80"},
+		{flag: "timeout", expectedType: "duration", expectedDef: "15s"},
+		{flag: "debug", expectedType: "bool", expectedDef: "false"},
+		{flag: "tags", expectedType: "stringSlice", expectedDef: "[a,b]"},
+		{flag: "name", expectedType: "string", expectedDef: ""},
+		{flag: "token", expectedType: "string", expectedDef: ""},
+	}
+
+	for _, tc := range testCases {
+		flag := fs.Lookup(tc.flag)
+		require.NotNil(t, flag, "flag --%s should be defined", tc.flag)
+		assert.Equal(t, tc.expectedType, flag.Value.Type())
+		assert.Equal(t, tc.expectedDef, flag.DefValue)
+	}
+
+	assert.Nil(t, fs.Lookup("no_flag"))
+	assert.Equal(t, "Port of the HTTP server (env HTTP_PORT)", fs.Lookup("port").Usage)
+	assert.Contains(t, fs.FlagUsages(), "Port of the HTTP server (env HTTP_PORT)")
+}
+
+func TestAutoConfigureWithFlags(t *testing.T) {
+	configs := []Config{
+		{Key: "from_flag", Flag: "from-flag", EnvironmentVar: "TEST_FLAG_FROM_FLAG", DefaultValue: "default"},
+		{Key: "from_env", Flag: "from-env", EnvironmentVar: "TEST_FLAG_FROM_ENV", DefaultValue: "default"},
+		{Key: "from_file", Flag: "from-file", EnvironmentVar: "TEST_FLAG_FROM_FILE", DefaultValue: "default"},
+		{Key: "from_default", Flag: "from-default", EnvironmentVar: "TEST_FLAG_FROM_DEFAULT", DefaultValue: "default"},
+	}
+
+	t.Setenv("TEST_FLAG_FROM_FLAG", "env")
+	t.Setenv("TEST_FLAG_FROM_ENV", "env")
+
+	dir := t.TempDir()
+	content := "from_flag: file\nfrom_env: file\nfrom_file: file\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(content), 0o600))
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	RegisterFlags(configs, fs)
+	require.NoError(t, fs.Parse([]string{"--from-flag", "flag"}))
+
+	v := viper.New()
+	require.NoError(t, AutoConfigure(configs, v, WithFlags(fs), WithConfigPaths(dir)))
+
+	assert.Equal(t, "flag", v.GetString("from_flag"))
+	assert.Equal(t, "env", v.GetString("from_env"))
+	assert.Equal(t, "file", v.GetString("from_file"))
+	assert.Equal(t, "default", v.GetString("from_default"))
+
+	assert.Equal(t, []ResolvedValue{
+		{Key: "from_flag", Value: "flag", Source: SourceFlag},
+		{Key: "from_env", Value: "env", Source: SourceEnv},
+		{Key: "from_file", Value: "file", Source: SourceFile},
+		{Key: "from_default", Value: "default", Source: SourceDefault},
+	}, Inspect(configs, v, WithFlags(fs)))
+}
+
+func TestAutoConfigureWithUndefinedFlag(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+
+	err := AutoConfigure([]Config{{Key: "port", Flag: "port"}}, viper.New(), WithFlags(fs))
+	require.ErrorIs(t, err, ErrBindFlag)
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// Source is the origin of a resolved configuration value.
+type Source string
+
+const (
+	// SourceUnset means that the option has no value.
+	SourceUnset Source = "unset"
+	// SourceDefault means that the value is the default value of the option.
+	SourceDefault Source = "default"
+	// SourceFile means that the value is read from the configuration file.
+	SourceFile Source = "file"
+	// SourceEnv means that the value is read from the environment variable of the option.
+	SourceEnv Source = "env"
+	// SourceFlag means that the value is read from the command-line flag of the option.
+	SourceFlag Source = "flag"
+)
+
+// ResolvedValue describes the effective value of a configuration option and where it comes from.
+type ResolvedValue struct {
+	// Key is the configuration key of the option.
+	Key ConfigKey `json:"key"`
+	// Value is the effective value of the option, it is Redacted for secret options.
+	Value any `json:"value"`
+	// Source is the origin of the value.
+	Source Source `json:"source"`
+}
+
+// Inspect returns the effective value and the source of every option, with the secret values redacted.
+//
+// The sources are checked in the same order as viper resolves the values: command-line flag,
+// environment variable, configuration file and default value.
+// The flags are only recognized when the flag set is passed with WithFlags.
+func Inspect(configs []Config, viperInstance *viper.Viper, opts ...Option) []ResolvedValue {
+	o := newOptions(opts)
+	values := make([]ResolvedValue, 0, len(configs))
+
+	for _, config := range configs {
+		if config.Key == "" {
+			continue
+		}
+
+		value := viperInstance.Get(string(config.Key))
+		if d, ok := value.(time.Duration); ok {
+			value = d.String()
+		}
+
+		values = append(values, ResolvedValue{
+			Key:    config.Key,
+			Value:  config.Redact(value),
+			Source: sourceOf(config, viperInstance, o.flags),
+		})
+	}
+
+	return values
+}
+
+// sourceOf returns the origin of the effective value of the option.
+func sourceOf(config Config, viperInstance *viper.Viper, flags *pflag.FlagSet) Source {
+	if config.Flag != "" && flags != nil && flags.Changed(config.Flag) {
+		return SourceFlag
+	}
+
+	if isEnvSet(config.EnvironmentVar) {
+		return SourceEnv
+	}
+
+	if viperInstance.InConfig(string(config.Key)) {
+		return SourceFile
+	}
+
+	if config.DefaultValue != nil {
+		return SourceDefault
+	}
+
+	return SourceUnset
+}
+
+// WriteResolvedValues writes the resolved values as an aligned, human-readable table.
+func WriteResolvedValues(w io.Writer, values []ResolvedValue) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	if _, err := fmt.Fprintln(tw, "KEY\tVALUE\tSOURCE"); err != nil {
+		return err
+	}
+
+	for _, value := range values {
+		if _, err := fmt.Fprintf(tw, "%s\t%v\t%s\n", value.Key, value.Value, value.Source); err != nil {
+			return err
+		}
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspect(t *testing.T) {
+	t.Setenv("TEST_INSPECT_HOST", "example.com")
+	// The empty environment variables are ignored
+	t.Setenv("TEST_INSPECT_PORT", "")
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("server:\n  port: 8080\n"), 0o600))
+
+	configs := []Config{
+		{Key: "server.host", EnvironmentVar: "TEST_INSPECT_HOST", DefaultValue: "localhost"},
+		{Key: "server.port", EnvironmentVar: "TEST_INSPECT_PORT", DefaultValue: 80},
+		{Key: "server.timeout", DefaultValue: 15 * time.Second},
+		{Key: "server.password", EnvironmentVar: "TEST_INSPECT_PASSWORD", DefaultValue: "hunter2", Secret: true},
+		{Key: "server.name"},
+	}
+
+	v := viper.New()
+	require.NoError(t, AutoConfigure(configs, v, WithConfigPaths(dir)))
+
+	assert.Equal(t, []ResolvedValue{
+		{Key: "server.host", Value: "example.com", Source: SourceEnv},
+		{Key: "server.port", Value: 8080, Source: SourceFile},
+		{Key: "server.timeout", Value: "15s", Source: SourceDefault},
+		{Key: "server.password", Value: Redacted, Source: SourceDefault},
+		{Key: "server.name", Value: nil, Source: SourceUnset},
+	}, Inspect(configs, v))
+}
+
+func TestWriteResolvedValues(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := WriteResolvedValues(&buf, []ResolvedValue{
+		{Key: "server.host", Value: "example.com", Source: SourceEnv},
+		{Key: "server.password", Value: Redacted, Source: SourceFile},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, ""+
+		"KEY              VALUE        SOURCE\n"+
+		"server.host      example.com  env\n"+
+		"server.password  [REDACTED]   file\n", buf.String())
+}
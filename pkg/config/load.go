@@ -0,0 +1,290 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/spf13/viper"
+)
+
+// Struct tags understood by FromStruct and Load.
+//
+//	type HTTPConfiguration struct {
+//		Port        int           `config:"port" env:"HTTP_PORT" default:"80" validate:"min=1,max=65535"`
+//		ReadTimeout time.Duration `config:"read_timeout" default:"15s" validate:"gt=0"`
+//	}
+const (
+	// TagConfig holds the name of the field in the configuration file.
+	// Nested structs extend the key of their parent, so a field tagged "port" inside a struct tagged "http"
+	// is stored under the "http.port" key.
+	TagConfig = "config"
+	// TagEnv holds the name of the environment variable bound to the field.
+	TagEnv = "env"
+	// TagFlag holds the name of the command-line flag bound to the field, see RegisterFlags.
+	TagFlag = "flag"
+	// TagDefault holds the default value of the field, written the same way as it would be in the configuration file.
+	TagDefault = "default"
+	// TagRequired marks the field as required when set to "true".
+	TagRequired = "required"
+	// TagSecret marks the field as secret when set to "true".
+	TagSecret = "secret"
+	// TagDeprecated marks the field as deprecated. The value is either "true",
+	// or the key of the option replacing the field.
+	TagDeprecated = "deprecated"
+	// TagDescription holds the human-readable description of the field.
+	TagDescription = "description"
+	// TagAllowed holds the comma separated list of the values the field can take.
+	TagAllowed = "allowed"
+)
+
+var (
+	durationType = reflect.TypeFor[time.Duration]()
+	timeType     = reflect.TypeFor[time.Time]()
+)
+
+// InvalidValueError is returned by Load when a resolved configuration value does not pass validation.
+type InvalidValueError struct {
+	// Key is the configuration key of the invalid value.
+	Key ConfigKey
+	// Rule is the validation rule that failed, e.g. "max".
+	Rule string
+	// Param is the parameter of the validation rule, e.g. "65535".
+	Param string
+	// Value is the value that failed the validation, it is Redacted for secret options.
+	Value any
+}
+
+func (e *InvalidValueError) Error() string {
+	rule := e.Rule
+	if e.Param != "" {
+		rule += "=" + e.Param
+	}
+
+	return fmt.Sprintf("invalid value %v for %q: failed on the %q rule", e.Value, e.Key, rule)
+}
+
+// FromStruct derives the configuration options from the struct tags of T.
+//
+// Only fields with a config tag are registered, fields tagged with "-" are skipped.
+// Fields of struct type are walked recursively, their keys are prefixed with the key of the parent field.
+func FromStruct[T any]() ([]Config, error) {
+	configs, _, err := walkStruct(reflect.TypeFor[T]())
+
+	return configs, err
+}
+
+// Load derives the configuration options from the struct tags of T, configures viper with AutoConfigure
+// using the provided options, decodes the resolved values into a new T and validates it with the provided validator.
+//
+// If the validator is nil, a new one is created.
+// Every problem, including the validation failures reported as InvalidValueError values, is returned as an Error.
+func Load[T any](viperInstance *viper.Viper, validate *validator.Validate, opts ...Option) (*T, error) {
+	configs, fields, err := walkStruct(reflect.TypeFor[T]())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := AutoConfigure(configs, viperInstance, opts...); err != nil {
+		return nil, err
+	}
+
+	return decodeStruct[T](viperInstance, validate, fields)
+}
+
+// StructValidator returns a reload validator for a Watcher, see WithReloadValidator, that decodes the configuration into a T
+// and validates it the same way as Load does.
+func StructValidator[T any](validate *validator.Validate) func(*viper.Viper) error {
+	return func(viperInstance *viper.Viper) error {
+		_, fields, err := walkStruct(reflect.TypeFor[T]())
+		if err != nil {
+			return err
+		}
+
+		_, err = decodeStruct[T](viperInstance, validate, fields)
+
+		return err
+	}
+}
+
+// decodeStruct decodes the resolved values into a new T and validates it.
+func decodeStruct[T any](viperInstance *viper.Viper, validate *validator.Validate, fields map[string]Config) (*T, error) {
+	result := new(T)
+	if err := viperInstance.Unmarshal(result, withConfigTag); err != nil {
+		return nil, newError(fmt.Errorf("failed to decode configuration: %w", err))
+	}
+
+	if validate == nil {
+		validate = validator.New()
+	}
+
+	if err := validate.Struct(result); err != nil {
+		return nil, translateValidationErrors(err, fields)
+	}
+
+	return result, nil
+}
+
+// withConfigTag makes mapstructure decode the struct using the config tag.
+func withConfigTag(dc *mapstructure.DecoderConfig) {
+	dc.TagName = TagConfig
+}
+
+// translateValidationErrors converts validator errors into InvalidValueError values,
+// using the configuration keys instead of the Go field names.
+func translateValidationErrors(err error, fields map[string]Config) error {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return newError(fmt.Errorf("failed to validate configuration: %w", err))
+	}
+
+	errs := make([]error, 0, len(validationErrors))
+	for _, fieldErr := range validationErrors {
+		// The elements of the slices and maps are validated with the dive rule, e.g. "Config.Hosts[0]"
+		namespace, index, _ := strings.Cut(fieldErr.StructNamespace(), "[")
+		if index != "" {
+			index = "[" + index
+		}
+
+		config, ok := fields[namespace]
+		if !ok {
+			config = Config{Key: ConfigKey(namespace)}
+		}
+
+		errs = append(errs, &InvalidValueError{
+			Key:   config.Key + ConfigKey(index),
+			Rule:  fieldErr.Tag(),
+			Param: fieldErr.Param(),
+			Value: config.Redact(fieldErr.Value()),
+		})
+	}
+
+	return newError(errs...)
+}
+
+// walkStruct collects the configuration options of a struct type.
+// Besides the options it returns the mapping between the struct namespaces used by the validator
+// (e.g. "Configuration.HTTP.Port") and the options.
+func walkStruct(t reflect.Type) ([]Config, map[string]Config, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, nil, fmt.Errorf("configuration must be a struct, got %s", t)
+	}
+
+	var (
+		configs []Config
+		fields  = map[string]Config{}
+	)
+
+	err := walkFields(t, "", t.Name(), &configs, fields)
+
+	return configs, fields, err
+}
+
+func walkFields(t reflect.Type, keyPrefix, namespace string, configs *[]Config, fields map[string]Config) error {
+	for i := range t.NumField() {
+		field := t.Field(i)
+
+		name, ok := field.Tag.Lookup(TagConfig)
+		if !ok || name == "-" || !field.IsExported() {
+			continue
+		}
+
+		key := keyPrefix + name
+		fieldNamespace := namespace + "." + field.Name
+
+		if field.Type.Kind() == reflect.Struct && field.Type != timeType {
+			if err := walkFields(field.Type, key+".", fieldNamespace, configs, fields); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		config, err := configFromField(field, ConfigKey(key))
+		if err != nil {
+			return err
+		}
+
+		*configs = append(*configs, config)
+		fields[fieldNamespace] = config
+	}
+
+	return nil
+}
+
+// configFromField builds the configuration option of a single struct field from its tags.
+func configFromField(field reflect.StructField, key ConfigKey) (Config, error) {
+	config := Config{
+		EnvironmentVar: field.Tag.Get(TagEnv),
+		Flag:           field.Tag.Get(TagFlag),
+		Key:            key,
+		Required:       field.Tag.Get(TagRequired) == "true",
+		Secret:         field.Tag.Get(TagSecret) == "true",
+		Description:    field.Tag.Get(TagDescription),
+	}
+
+	if deprecated, ok := field.Tag.Lookup(TagDeprecated); ok {
+		config.Deprecated = true
+		if deprecated != "true" {
+			config.ReplacedBy = ConfigKey(deprecated)
+		}
+	}
+
+	if def, ok := field.Tag.Lookup(TagDefault); ok {
+		value, err := decodeDefault(def, field.Type)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid default value for %q: %w", key, err)
+		}
+
+		config.DefaultValue = value
+	}
+
+	if allowed, ok := field.Tag.Lookup(TagAllowed); ok {
+		for _, a := range strings.Split(allowed, ",") {
+			value, err := decodeDefault(a, field.Type)
+			if err != nil {
+				return Config{}, fmt.Errorf("invalid allowed value for %q: %w", key, err)
+			}
+
+			config.AllowedValues = append(config.AllowedValues, value)
+		}
+	}
+
+	return config, nil
+}
+
+// decodeDefault converts the textual default value of a struct tag into the type of the field.
+func decodeDefault(def string, t reflect.Type) (any, error) {
+	if t.Kind() == reflect.String {
+		return def, nil
+	}
+
+	if t == durationType {
+		return time.ParseDuration(def)
+	}
+
+	var input any = def
+	if t.Kind() == reflect.Slice {
+		input = strings.Split(def, ",")
+	}
+
+	result := reflect.New(t)
+
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		WeaklyTypedInput: true,
+		Result:           result.Interface(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := decoder.Decode(input); err != nil {
+		return nil, err
+	}
+
+	return result.Elem().Interface(), nil
+}
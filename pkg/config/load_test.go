@@ -0,0 +1,166 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testServerConfig struct {
+	Host    string        `config:"host" env:"TEST_LOAD_HOST" default:"localhost"`
+	Port    int           `config:"port" env:"TEST_LOAD_PORT" default:"80" validate:"min=1,max=65535"`
+	Timeout time.Duration `config:"timeout" default:"15s" validate:"gt=0"`
+}
+
+type testLoadConfig struct {
+	Server  testServerConfig `config:"server"`
+	Tags    []string         `config:"tags" default:"a,b"`
+	Ignored string
+	Skipped string `config:"-"`
+}
+
+func TestFromStruct(t *testing.T) {
+	configs, err := FromStruct[testLoadConfig]()
+	require.NoError(t, err)
+
+	assert.Equal(t, []Config{
+		{Key: "server.host", EnvironmentVar: "TEST_LOAD_HOST", DefaultValue: "localhost"},
+		{Key: "server.port", EnvironmentVar: "TEST_LOAD_PORT", DefaultValue: 80},
+		{Key: "server.timeout", DefaultValue: 15 * time.Second},
+		{Key: "tags", DefaultValue: []string{"a", "b"}},
+	}, configs)
+}
+
+func TestFromStructMetadata(t *testing.T) {
+	type metadataConfig struct {
+		Password string `config:"password" required:"true" secret:"true" description:"Database password"`
+		Format   string `config:"format" allowed:"json,text"`
+		Level    int    `config:"level" allowed:"1,2"`
+		OldPort  int    `config:"old_port" deprecated:"port"`
+		OldHost  string `config:"old_host" deprecated:"true"`
+	}
+
+	configs, err := FromStruct[metadataConfig]()
+	require.NoError(t, err)
+
+	assert.Equal(t, []Config{
+		{Key: "password", Required: true, Secret: true, Description: "Database password"},
+		{Key: "format", AllowedValues: []any{"json", "text"}},
+		{Key: "level", AllowedValues: []any{1, 2}},
+		{Key: "old_port", Deprecated: true, ReplacedBy: "port"},
+		{Key: "old_host", Deprecated: true},
+	}, configs)
+}
+
+func TestLoadRedactsSecrets(t *testing.T) {
+	type secretConfig struct {
+		Token string `config:"token" env:"TEST_LOAD_TOKEN" secret:"true" validate:"len=32"`
+	}
+
+	t.Setenv("TEST_LOAD_TOKEN", "hunter2")
+
+	_, err := Load[secretConfig](viper.New(), nil)
+
+	var invalidValueErr *InvalidValueError
+	require.ErrorAs(t, err, &invalidValueErr)
+	assert.Equal(t, Redacted, invalidValueErr.Value)
+	assert.NotContains(t, err.Error(), "hunter2")
+}
+
+func TestFromStructInvalid(t *testing.T) {
+	type invalidDefault struct {
+		Port int `config:"port" default:"eighty"`
+	}
+
+	_, err := FromStruct[invalidDefault]()
+	require.ErrorContains(t, err, `invalid default value for "port"`)
+
+	type invalidAllowed struct {
+		Port int `config:"port" allowed:"80,eighty"`
+	}
+
+	_, err = FromStruct[invalidAllowed]()
+	require.ErrorContains(t, err, `invalid allowed value for "port"`)
+
+	_, err = FromStruct[string]()
+	require.Error(t, err)
+}
+
+func TestInvalidValueError(t *testing.T) {
+	err := &InvalidValueError{Key: "server.port", Rule: "max", Param: "65535", Value: 70000}
+	assert.EqualError(t, err, `invalid value 70000 for "server.port": failed on the "max=65535" rule`)
+
+	err = &InvalidValueError{Key: "server.host", Rule: "required", Value: ""}
+	assert.EqualError(t, err, `invalid value  for "server.host": failed on the "required" rule`)
+}
+
+func TestLoad(t *testing.T) {
+	testCases := []struct {
+		name        string
+		envVars     map[string]string
+		expected    *testLoadConfig
+		expectedErr *InvalidValueError
+	}{
+		{
+			name: "defaults only",
+			expected: &testLoadConfig{
+				Server: testServerConfig{Host: "localhost", Port: 80, Timeout: 15 * time.Second},
+				Tags:   []string{"a", "b"},
+			},
+		},
+		{
+			name:    "environment overrides",
+			envVars: map[string]string{"TEST_LOAD_HOST": "example.com", "TEST_LOAD_PORT": "8080"},
+			expected: &testLoadConfig{
+				Server: testServerConfig{Host: "example.com", Port: 8080, Timeout: 15 * time.Second},
+				Tags:   []string{"a", "b"},
+			},
+		},
+		{
+			name:        "invalid port",
+			envVars:     map[string]string{"TEST_LOAD_PORT": "70000"},
+			expectedErr: &InvalidValueError{Key: "server.port", Rule: "max", Param: "65535", Value: 70000},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for k, v := range tc.envVars {
+				t.Setenv(k, v)
+			}
+
+			cfg, err := Load[testLoadConfig](viper.New(), nil)
+
+			if tc.expectedErr == nil {
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, cfg)
+
+				return
+			}
+
+			require.Error(t, err)
+			assert.Nil(t, cfg)
+
+			var invalidValueErr *InvalidValueError
+			require.ErrorAs(t, err, &invalidValueErr)
+			assert.Equal(t, tc.expectedErr, invalidValueErr)
+		})
+	}
+}
+
+func TestLoadSliceElements(t *testing.T) {
+	type sliceConfig struct {
+		Proxies []string `config:"proxies" env:"TEST_LOAD_PROXIES" validate:"dive,ip"`
+	}
+
+	t.Setenv("TEST_LOAD_PROXIES", "10.0.0.1,invalid")
+
+	_, err := Load[sliceConfig](viper.New(), nil)
+
+	var invalidValueErr *InvalidValueError
+	require.ErrorAs(t, err, &invalidValueErr)
+	assert.Equal(t, &InvalidValueError{Key: "proxies[1]", Rule: "ip", Value: "invalid"}, invalidValueErr)
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// Option configures AutoConfigure, Load, Inspect and NewWatcher.
+type Option func(*options)
+
+type options struct {
+	flags       *pflag.FlagSet
+	validators  []func(*viper.Viper) error
+	serviceName string
+	configFile  string
+	configPaths []string
+	formats     []string
+	overlays    []string
+	resolvers   map[string]Resolver
+}
+
+// WithResolver registers a resolver for the references with the given scheme, e.g. "vault" for "vault:db/password".
+// It replaces the resolver registered for the scheme, including the default "file" and "env" resolvers.
+func WithResolver(scheme string, resolver Resolver) Option {
+	return func(o *options) {
+		o.resolvers[scheme] = resolver
+	}
+}
+
+// WithServiceName sets the service name used in the configuration search paths,
+// the configuration file is looked for in the current directory, /etc/<name>/ and $HOME/.config/<name>/.
+// It defaults to DefaultServiceName.
+func WithServiceName(name string) Option {
+	return func(o *options) {
+		o.serviceName = name
+	}
+}
+
+// WithConfigFile sets the path of the configuration file explicitly, instead of looking for it in the search paths.
+// It takes precedence over the --config flag and the CONFIG_FILE environment variable.
+// Unlike a file in the search paths, an explicitly set file must exist.
+func WithConfigFile(path string) Option {
+	return func(o *options) {
+		o.configFile = path
+	}
+}
+
+// WithConfigPaths replaces the directories where the configuration file is looked for.
+// Environment variables in the paths, like $HOME, are expanded.
+func WithConfigPaths(paths ...string) Option {
+	return func(o *options) {
+		o.configPaths = paths
+	}
+}
+
+// WithFormats sets the accepted configuration file formats in the order of preference, see SupportedFormats.
+// It defaults to YAML only.
+func WithFormats(formats ...string) Option {
+	return func(o *options) {
+		o.formats = formats
+	}
+}
+
+// WithOverlays sets the overlays merged on top of the base configuration file, in order.
+// For the "production" overlay of config.yaml, config.production.yaml is merged if it exists next to the base file.
+// Missing overlays are skipped.
+func WithOverlays(overlays ...string) Option {
+	return func(o *options) {
+		o.overlays = append(o.overlays, overlays...)
+	}
+}
+
+// WithFlags binds the command-line flags of the options to the parsed flag set.
+// The flags must be defined with RegisterFlags before the flag set is parsed.
+func WithFlags(flags *pflag.FlagSet) Option {
+	return func(o *options) {
+		o.flags = flags
+	}
+}
+
+// WithReloadValidator adds a validator that is called with the candidate configuration on every reload
+// of a Watcher, see StructValidator.
+func WithReloadValidator(validator func(*viper.Viper) error) Option {
+	return func(o *options) {
+		o.validators = append(o.validators, validator)
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{resolvers: defaultResolvers()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
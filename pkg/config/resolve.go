@@ -0,0 +1,104 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Resolver resolves the references in configuration values to the actual values,
+// e.g. the path of a mounted secret to the content of the file.
+type Resolver interface {
+	// Resolve returns the value the reference points to.
+	// The reference is the configuration value without the scheme, e.g. "/run/secrets/db_password"
+	// for "file:///run/secrets/db_password".
+	Resolve(reference string) (string, error)
+}
+
+// ResolverFunc is an adapter to allow the use of ordinary functions as resolvers.
+type ResolverFunc func(reference string) (string, error)
+
+// Resolve calls f(reference).
+func (f ResolverFunc) Resolve(reference string) (string, error) {
+	return f(reference)
+}
+
+// FileResolver resolves "file://" references to the content of the file, without the trailing line breaks.
+// It is registered by default for the "file" scheme.
+var FileResolver = ResolverFunc(func(reference string) (string, error) {
+	content, err := os.ReadFile(reference) //nolint:gosec // reading the referenced secret file is intended
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(content), "\r\n"), nil
+})
+
+// EnvResolver resolves "env:" references to the value of the environment variable.
+// It is registered by default for the "env" scheme.
+var EnvResolver = ResolverFunc(func(reference string) (string, error) {
+	value, ok := os.LookupEnv(reference)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", reference)
+	}
+
+	return value, nil
+})
+
+// defaultResolvers returns the resolvers registered by default, by their schemes.
+func defaultResolvers() map[string]Resolver {
+	return map[string]Resolver{
+		"file": FileResolver,
+		"env":  EnvResolver,
+	}
+}
+
+// parseReference splits a configuration value into the scheme and the reference,
+// if the scheme has a registered resolver. Both "scheme:reference" and "scheme://reference" forms are accepted.
+func parseReference(value string, resolvers map[string]Resolver) (Resolver, string, bool) {
+	scheme, reference, ok := strings.Cut(value, ":")
+	if !ok {
+		return nil, "", false
+	}
+
+	resolver, ok := resolvers[scheme]
+	if !ok {
+		return nil, "", false
+	}
+
+	return resolver, strings.TrimPrefix(reference, "//"), true
+}
+
+// resolveReferences replaces the string values that are references with the values they point to.
+func resolveReferences(configs []Config, viperInstance *viper.Viper, o options) error {
+	var errs []error
+
+	for _, config := range configs {
+		if config.Key == "" {
+			continue
+		}
+
+		value, ok := viperInstance.Get(string(config.Key)).(string)
+		if !ok {
+			continue
+		}
+
+		resolver, reference, ok := parseReference(value, o.resolvers)
+		if !ok {
+			continue
+		}
+
+		resolved, err := resolver.Resolve(reference)
+		if err != nil {
+			errs = append(errs, &OptionError{Key: config.Key, Err: fmt.Errorf("%w %q: %w", ErrUnresolvedReference, value, err)})
+
+			continue
+		}
+
+		viperInstance.Set(string(config.Key), resolved)
+	}
+
+	return newError(errs...)
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryResolver is a local stand-in for a secret store backend.
+type memoryResolver map[string]string
+
+func (m memoryResolver) Resolve(reference string) (string, error) {
+	value, ok := m[reference]
+	if !ok {
+		return "", errors.New("secret not found")
+	}
+
+	return value, nil
+}
+
+func TestResolveReferences(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "db_password")
+	require.NoError(t, os.WriteFile(secretFile, []byte("hunter2\n"), 0o600))
+
+	t.Setenv("TEST_RESOLVE_OTHER_VAR", "from other var")
+
+	testCases := []struct {
+		name        string
+		value       string
+		opts        []Option
+		expected    string
+		expectedErr error
+	}{
+		{
+			name:     "file reference",
+			value:    "file://" + secretFile,
+			expected: "hunter2",
+		},
+		{
+			name:     "env reference",
+			value:    "env:TEST_RESOLVE_OTHER_VAR",
+			expected: "from other var",
+		},
+		{
+			name:     "custom resolver",
+			value:    "vault:db/password",
+			opts:     []Option{WithResolver("vault", memoryResolver{"db/password": "s3cr3t"})},
+			expected: "s3cr3t",
+		},
+		{
+			name:     "unknown scheme is a literal value",
+			value:    "https://example.com",
+			expected: "https://example.com",
+		},
+		{
+			name:     "literal value",
+			value:    "hunter2",
+			expected: "hunter2",
+		},
+		{
+			name:        "missing file",
+			value:       "file:///non/existent/secret",
+			expectedErr: ErrUnresolvedReference,
+		},
+		{
+			name:        "missing env",
+			value:       "env:TEST_RESOLVE_MISSING_VAR",
+			expectedErr: ErrUnresolvedReference,
+		},
+		{
+			name:        "custom resolver failure",
+			value:       "vault:db/username",
+			opts:        []Option{WithResolver("vault", memoryResolver{})},
+			expectedErr: ErrUnresolvedReference,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("TEST_RESOLVE_VALUE", tc.value)
+
+			v := viper.New()
+			err := ConfigureFromEnv([]Config{
+				{Key: "db.password", EnvironmentVar: "TEST_RESOLVE_VALUE", Secret: true},
+			}, v, tc.opts...)
+
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, v.GetString("db.password"))
+		})
+	}
+}
+
+func TestWatcherReloadResolvesReferences(t *testing.T) {
+	dir := t.TempDir()
+	secretFile := filepath.Join(dir, "db_password")
+	writeFiles(t, dir, map[string]string{
+		"config.yaml": "db:\n  password: file://" + secretFile + "\n",
+		"db_password": "hunter2",
+	})
+
+	configs := []Config{{Key: "db.password", Secret: true}}
+	opts := []Option{WithConfigPaths(dir)}
+
+	v := viper.New()
+	require.NoError(t, AutoConfigure(configs, v, opts...))
+	assert.Equal(t, "hunter2", v.GetString("db.password"))
+
+	w, err := newTestWatcher(configs, v, opts...)
+	require.NoError(t, err)
+
+	writeFiles(t, dir, map[string]string{"db_password": "rotated"})
+	require.NoError(t, w.Reload())
+	assert.Equal(t, "rotated", v.GetString("db.password"))
+
+	writeFiles(t, dir, map[string]string{"config.yaml": "db:\n  password: literal\n"})
+	require.NoError(t, w.Reload())
+	assert.Equal(t, "literal", v.GetString("db.password"))
+}
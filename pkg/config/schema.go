@@ -0,0 +1,136 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// JSONSchemaDialect is the JSON Schema dialect of the generated schemas.
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// durationPattern matches the durations accepted by time.ParseDuration.
+const durationPattern = `^[-+]?([0-9]*(\.[0-9]*)?(ns|us|µs|ms|s|m|h))+$`
+
+// Schema is a JSON Schema describing a configuration file, it can be marshaled with encoding/json.
+type Schema struct {
+	Schema      string             `json:"$schema,omitempty"`
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Type        string             `json:"type,omitempty"`
+	Format      string             `json:"format,omitempty"`
+	Pattern     string             `json:"pattern,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Enum        []any              `json:"enum,omitempty"`
+	Default     any                `json:"default,omitempty"`
+	Deprecated  bool               `json:"deprecated,omitempty"`
+	WriteOnly   bool               `json:"writeOnly,omitempty"`
+}
+
+// JSONSchema returns the JSON Schema of the configuration files of the options,
+// which editors can use to validate and complete the files.
+//
+// The types are derived from the default values, options without a default value accept any value.
+// The required options are not required by the schema, as they can be set by environment variables and flags as well.
+func JSONSchema(configs []Config) *Schema {
+	root := &Schema{Schema: JSONSchemaDialect, Type: "object"}
+
+	for _, config := range configs {
+		if config.Key == "" {
+			continue
+		}
+
+		parent := root
+		segments := strings.Split(string(config.Key), ".")
+
+		for _, segment := range segments[:len(segments)-1] {
+			if parent.Properties == nil {
+				parent.Properties = map[string]*Schema{}
+			}
+
+			child, ok := parent.Properties[segment]
+			if !ok {
+				child = &Schema{Type: "object"}
+				parent.Properties[segment] = child
+			}
+
+			parent = child
+		}
+
+		if parent.Properties == nil {
+			parent.Properties = map[string]*Schema{}
+		}
+
+		parent.Properties[segments[len(segments)-1]] = optionSchema(config)
+	}
+
+	return root
+}
+
+// optionSchema returns the schema of a single option.
+func optionSchema(config Config) *Schema {
+	schema := &Schema{
+		Description: strings.Join(append(describe(config), describeSources(config)...), " "),
+		Enum:        config.AllowedValues,
+		Deprecated:  config.Deprecated,
+		WriteOnly:   config.Secret,
+	}
+
+	if config.DefaultValue != nil {
+		setSchemaType(schema, reflect.TypeOf(config.DefaultValue))
+
+		if !config.Secret {
+			schema.Default = exampleValue(config.DefaultValue)
+		}
+	}
+
+	return schema
+}
+
+// setSchemaType sets the type of the schema, and the format or pattern of the values, based on the Go type.
+func setSchemaType(schema *Schema, t reflect.Type) {
+	switch {
+	case t == durationType:
+		schema.Type = "string"
+		schema.Pattern = durationPattern
+
+		return
+	case t == timeType:
+		schema.Type = "string"
+		schema.Format = "date-time"
+
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		schema.Type = "string"
+	case reflect.Bool:
+		schema.Type = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		schema.Type = "integer"
+	case reflect.Float32, reflect.Float64:
+		schema.Type = "number"
+	case reflect.Slice, reflect.Array:
+		schema.Type = "array"
+		schema.Items = &Schema{}
+		setSchemaType(schema.Items, t.Elem())
+	case reflect.Map, reflect.Struct:
+		schema.Type = "object"
+	default:
+	}
+}
+
+// exampleValue returns the value in the form it is written to the configuration files.
+func exampleValue(value any) any {
+	switch v := value.(type) {
+	case time.Duration:
+		return v.String()
+	case time.Time:
+		return v.Format(time.RFC3339)
+	default:
+		return value
+	}
+}
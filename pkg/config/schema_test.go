@@ -0,0 +1,55 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONSchema(t *testing.T) {
+	schema := JSONSchema([]Config{
+		{Key: "http.port", DefaultValue: 80, Description: "Port of the HTTP server", EnvironmentVar: "HTTP_PORT"},
+		{Key: "http.timeout", DefaultValue: 15 * time.Second},
+		{Key: "log.level", DefaultValue: "info", AllowedValues: []any{"debug", "info"}},
+		{Key: "tags", DefaultValue: []string{"a"}},
+		{Key: "token", DefaultValue: "hunter2", Secret: true, Required: true},
+		{Key: "legacy_port", DefaultValue: 80, Deprecated: true, ReplacedBy: "http.port"},
+		{Key: "anything"},
+	})
+
+	assert.Equal(t, JSONSchemaDialect, schema.Schema)
+	assert.Equal(t, "object", schema.Type)
+
+	http := schema.Properties["http"]
+	require.NotNil(t, http)
+	assert.Equal(t, "object", http.Type)
+	assert.Equal(t, &Schema{
+		Description: "Port of the HTTP server. Environment variable: HTTP_PORT.",
+		Type:        "integer",
+		Default:     80,
+	}, http.Properties["port"])
+	assert.Equal(t, &Schema{Type: "string", Pattern: durationPattern, Default: "15s"}, http.Properties["timeout"])
+
+	assert.Equal(t, &Schema{
+		Description: "Allowed values: debug, info.",
+		Type:        "string",
+		Enum:        []any{"debug", "info"},
+		Default:     "info",
+	}, schema.Properties["log"].Properties["level"])
+	assert.Equal(t, &Schema{Type: "array", Items: &Schema{Type: "string"}, Default: []string{"a"}}, schema.Properties["tags"])
+	assert.Equal(t, &Schema{Description: "Required. Secret.", Type: "string", WriteOnly: true}, schema.Properties["token"])
+	assert.Equal(t, &Schema{
+		Description: "Deprecated, use http.port instead.",
+		Type:        "integer",
+		Default:     80,
+		Deprecated:  true,
+	}, schema.Properties["legacy_port"])
+	assert.Equal(t, &Schema{}, schema.Properties["anything"])
+
+	out, err := json.Marshal(schema.Properties["token"])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"description":"Required. Secret.","type":"string","writeOnly":true}`, string(out))
+}
@@ -0,0 +1,305 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"reflect"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+)
+
+// ChangeEvent describes the change of a single configuration option after a reload.
+type ChangeEvent struct {
+	// Key is the configuration key of the changed option.
+	Key ConfigKey
+	// OldValue is the value before the reload.
+	OldValue any
+	// NewValue is the value after the reload.
+	NewValue any
+}
+
+// Subscriber is a function that is called with the change events of the options it subscribed to.
+type Subscriber func(event ChangeEvent)
+
+// Watcher reloads the configuration file and its overlays when they change on the disk,
+// and notifies the subscribers about the changes.
+//
+// Every reload is resolved and verified the same way as AutoConfigure does, and with the optional reload validators.
+// Invalid reloads are rejected and logged, the last good configuration stays active.
+//
+// The environment variables and the command-line flags are not re-read on reload,
+// they still override the values of the file.
+type Watcher struct {
+	configs       []Config
+	viperInstance *viper.Viper
+	opts          options
+	files         []string
+	// viperLock guards the writes of the viper instance, it is shared with the Configuration owning the instance.
+	viperLock sync.Locker
+
+	mu          sync.Mutex
+	values      map[ConfigKey]any
+	subscribers map[ConfigKey][]Subscriber
+}
+
+// newWatcher creates a new Watcher for a viper instance already configured with AutoConfigure.
+// The viper instance is written on every reload, so it must only be read while holding the viperLock,
+// that is why the Watcher is only created by Configuration.NewWatcher.
+//
+// It returns an error wrapping ErrConfigFileNotFound if there is no configuration file to watch.
+func newWatcher(configs []Config, viperInstance *viper.Viper, viperLock sync.Locker, o options) (*Watcher, error) {
+	files, err := resolveConfigFiles(viperInstance, o)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%w: nothing to watch", ErrConfigFileNotFound)
+	}
+
+	return &Watcher{
+		configs:       configs,
+		viperInstance: viperInstance,
+		opts:          o,
+		files:         files,
+		viperLock:     viperLock,
+		values:        currentValues(configs, viperInstance),
+		subscribers:   map[ConfigKey][]Subscriber{},
+	}, nil
+}
+
+// Subscribe registers a subscriber for the changes of the given key.
+// If the key is empty, the subscriber is notified about the changes of every key.
+func (w *Watcher) Subscribe(key ConfigKey, subscriber Subscriber) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.subscribers[key] = append(w.subscribers[key], subscriber)
+}
+
+// OnChange registers a typed subscriber for the changes of the given key.
+// The old and new values are converted to T, changes that cannot be converted are logged and skipped.
+func OnChange[T cast.Basic](w *Watcher, key ConfigKey, fn func(oldValue, newValue T)) {
+	w.Subscribe(key, func(event ChangeEvent) {
+		oldValue, oldErr := cast.ToE[T](event.OldValue)
+		newValue, newErr := cast.ToE[T](event.NewValue)
+
+		if oldErr != nil || newErr != nil {
+			slog.Error("failed to convert configuration change", "key", key, "type", fmt.Sprintf("%T", oldValue))
+
+			return
+		}
+
+		fn(oldValue, newValue)
+	})
+}
+
+// Run watches the configuration files and reloads them on every change, until the context is cancelled.
+//
+// The directories of the files are watched instead of the files themselves,
+// so editors replacing the files and Kubernetes swapping the symlinks of mounted ConfigMaps are handled too.
+func (w *Watcher) Run(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	defer watcher.Close() //nolint:errcheck // nothing to do with the error on shutdown
+
+	realFiles := make(map[string]string, len(w.files))
+
+	for _, file := range w.files {
+		file = filepath.Clean(file)
+		realFiles[file], _ = filepath.EvalSymlinks(file)
+
+		if err := watcher.Add(filepath.Dir(file)); err != nil {
+			return fmt.Errorf("failed to watch configuration file: %w", err)
+		}
+	}
+
+	slog.Info("watching configuration files", "files", w.files)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+
+			if !filesChanged(event, realFiles) {
+				continue
+			}
+
+			// The error is already logged, the last good configuration stays active.
+			_ = w.Reload()
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+
+			slog.Error("configuration file watcher failed", "error", err)
+		}
+	}
+}
+
+// filesChanged reports whether the event changed any of the watched files,
+// either by writing them or by changing the target of their symlinks. It updates the targets of the symlinks.
+func filesChanged(event fsnotify.Event, realFiles map[string]string) bool {
+	changed := false
+
+	for file, realFile := range realFiles {
+		currentRealFile, _ := filepath.EvalSymlinks(file)
+
+		if filepath.Clean(event.Name) == file && event.Has(fsnotify.Write|fsnotify.Create) {
+			changed = true
+		}
+
+		if currentRealFile != "" && currentRealFile != realFile {
+			realFiles[file] = currentRealFile
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+// Reload re-reads the configuration files, verifies them, and if they are valid,
+// applies it to the viper instance and notifies the subscribers about the changed values.
+//
+// If the new configuration is invalid, the error is logged and returned, and the last good configuration stays active.
+func (w *Watcher) Reload() error {
+	w.mu.Lock()
+
+	events, err := w.reload()
+
+	subscribers := make(map[ConfigKey][]Subscriber, len(w.subscribers))
+	for key, s := range w.subscribers {
+		subscribers[key] = s
+	}
+
+	w.mu.Unlock()
+
+	if err != nil {
+		slog.Error("rejected configuration reload, keeping the last good configuration", "error", err)
+
+		return err
+	}
+
+	for _, event := range events {
+		for _, subscriber := range subscribers[event.Key] {
+			subscriber(event)
+		}
+
+		for _, subscriber := range subscribers[""] {
+			subscriber(event)
+		}
+	}
+
+	return nil
+}
+
+func (w *Watcher) reload() ([]ChangeEvent, error) {
+	files, err := readConfigFiles(w.files)
+	if err != nil {
+		return nil, newError(err)
+	}
+
+	candidate := viper.New()
+	if err := w.apply(candidate, files); err != nil {
+		return nil, err
+	}
+
+	for _, validate := range w.opts.validators {
+		if err := validate(candidate); err != nil {
+			return nil, err
+		}
+	}
+
+	values, err := w.commit(candidate, files)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []ChangeEvent
+
+	for _, config := range w.configs {
+		oldValue, newValue := w.values[config.Key], values[config.Key]
+		if reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+
+		slog.Info("configuration changed", "key", config.Key,
+			"old", config.Redact(oldValue), "new", config.Redact(newValue))
+
+		events = append(events, ChangeEvent{Key: config.Key, OldValue: oldValue, NewValue: newValue})
+	}
+
+	w.values = values
+
+	return events, nil
+}
+
+// commit applies the verified candidate to the viper instance and returns the new values of the options.
+//
+// The files are read into the viper instance, replacing its previous configuration, so the removed keys are removed,
+// and the flags and the environment variables keep their precedence. Only the values the candidate resolved
+// differently, e.g. the references and the deprecated options, are copied from it, so they are not resolved twice.
+// The values copied by the previous reloads are cleared first, viper ignores the nil overrides.
+func (w *Watcher) commit(candidate *viper.Viper, files []configFile) (map[ConfigKey]any, error) {
+	w.viperLock.Lock()
+	defer w.viperLock.Unlock()
+
+	if err := applyConfigFiles(w.viperInstance, files); err != nil {
+		return nil, newError(err)
+	}
+
+	for _, config := range w.configs {
+		if config.Key == "" {
+			continue
+		}
+
+		key := string(config.Key)
+		w.viperInstance.Set(key, nil)
+
+		if resolved := candidate.Get(key); !reflect.DeepEqual(w.viperInstance.Get(key), resolved) {
+			w.viperInstance.Set(key, resolved)
+		}
+	}
+
+	return currentValues(w.configs, w.viperInstance), nil
+}
+
+// apply configures the viper instance from the content of the configuration files,
+// the environment variables and the command-line flags, and resolves and verifies the values.
+func (w *Watcher) apply(viperInstance *viper.Viper, files []configFile) error {
+	err := newError(registerConfigOptions(w.configs, viperInstance), bindFlags(w.configs, viperInstance, w.opts.flags))
+	if err != nil {
+		return err
+	}
+
+	viperInstance.AutomaticEnv()
+
+	if err := applyConfigFiles(viperInstance, files); err != nil {
+		return newError(err)
+	}
+
+	return resolveAndVerify(w.configs, viperInstance, w.opts)
+}
+
+// currentValues returns the resolved values of the options.
+func currentValues(configs []Config, viperInstance *viper.Viper) map[ConfigKey]any {
+	values := make(map[ConfigKey]any, len(configs))
+	for _, config := range configs {
+		if config.Key != "" {
+			values[config.Key] = viperInstance.Get(string(config.Key))
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,226 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var watchTestConfigs = []Config{
+	{Key: "log.level", DefaultValue: "info", AllowedValues: []any{"debug", "info", "warn", "error"}},
+	{Key: "rate_limit", DefaultValue: 10},
+}
+
+func setupWatcher(t *testing.T, content string, opts ...Option) (*Watcher, *viper.Viper, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	opts = append(opts, WithConfigPaths(dir))
+
+	v := viper.New()
+	require.NoError(t, AutoConfigure(watchTestConfigs, v, opts...))
+
+	w, err := newTestWatcher(watchTestConfigs, v, opts...)
+	require.NoError(t, err)
+
+	return w, v, path
+}
+
+// newTestWatcher creates a Watcher for a viper instance that is only read by the test itself.
+func newTestWatcher(configs []Config, v *viper.Viper, opts ...Option) (*Watcher, error) {
+	return newWatcher(configs, v, &sync.Mutex{}, newOptions(opts))
+}
+
+func TestNewWatcherWithoutFile(t *testing.T) {
+	_, err := newTestWatcher(watchTestConfigs, viper.New())
+	require.ErrorIs(t, err, ErrConfigFileNotFound)
+}
+
+func TestWatcherReload(t *testing.T) {
+	testCases := []struct {
+		name          string
+		content       string
+		validator     func(*viper.Viper) error
+		expectedErr   error
+		expected      map[string]interface{}
+		expectedEvent []ChangeEvent
+	}{
+		{
+			name:     "valid change",
+			content:  "log:\n  level: debug\nrate_limit: 10\n",
+			expected: map[string]interface{}{"log.level": "debug", "rate_limit": 10},
+			expectedEvent: []ChangeEvent{
+				{Key: "log.level", OldValue: "info", NewValue: "debug"},
+			},
+		},
+		{
+			name:        "malformed file",
+			content:     "log: [debug\n",
+			expectedErr: ErrMalformedConfigFile,
+			expected:    map[string]interface{}{"log.level": "info", "rate_limit": 10},
+		},
+		{
+			name:        "value not allowed",
+			content:     "log:\n  level: verbose\n",
+			expectedErr: ErrValueNotAllowed,
+			expected:    map[string]interface{}{"log.level": "info", "rate_limit": 10},
+		},
+		{
+			name:    "rejected by validator",
+			content: "rate_limit: 0\n",
+			validator: func(v *viper.Viper) error {
+				if v.GetInt("rate_limit") < 1 {
+					return ErrValueNotAllowed
+				}
+
+				return nil
+			},
+			expectedErr: ErrValueNotAllowed,
+			expected:    map[string]interface{}{"log.level": "info", "rate_limit": 10},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var opts []Option
+			if tc.validator != nil {
+				opts = append(opts, WithReloadValidator(tc.validator))
+			}
+
+			w, v, path := setupWatcher(t, "log:\n  level: info\n", opts...)
+
+			var events []ChangeEvent
+			w.Subscribe("", func(event ChangeEvent) {
+				events = append(events, event)
+			})
+
+			require.NoError(t, os.WriteFile(path, []byte(tc.content), 0o600))
+
+			err := w.Reload()
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			for key, expected := range tc.expected {
+				assert.Equal(t, expected, v.Get(key), "Value for %s should be the last good value", key)
+			}
+
+			assert.Equal(t, tc.expectedEvent, events)
+		})
+	}
+}
+
+func TestWatcherReloadPrecedence(t *testing.T) {
+	w, v, path := setupWatcher(t, "rate_limit: 20\n")
+
+	require.NoError(t, os.WriteFile(path, []byte("log:\n  level: debug\n"), 0o600))
+	require.NoError(t, w.Reload())
+
+	// The key removed from the file falls back to its default value
+	assert.Equal(t, 10, v.GetInt("rate_limit"))
+	assert.False(t, v.InConfig("rate_limit"))
+	assert.True(t, v.InConfig("log.level"))
+
+	// The environment variables still override the values of the file
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	t.Setenv("LOG_LEVEL", "warn")
+	assert.Equal(t, "warn", v.GetString("log.level"))
+}
+
+func TestOnChange(t *testing.T) {
+	w, _, path := setupWatcher(t, "rate_limit: 10\n")
+
+	var oldLimit, newLimit int
+
+	OnChange(w, "rate_limit", func(oldValue, newValue int) {
+		oldLimit, newLimit = oldValue, newValue
+	})
+
+	called := false
+
+	w.Subscribe("log.level", func(ChangeEvent) {
+		called = true
+	})
+
+	require.NoError(t, os.WriteFile(path, []byte("rate_limit: \"20\"\n"), 0o600))
+	require.NoError(t, w.Reload())
+
+	assert.Equal(t, 10, oldLimit)
+	assert.Equal(t, 20, newLimit)
+	assert.False(t, called, "subscribers of other keys should not be notified")
+}
+
+func TestWatcherRun(t *testing.T) {
+	w, _, path := setupWatcher(t, "rate_limit: 10\n")
+
+	changes := make(chan int, 1)
+
+	OnChange(w, "rate_limit", func(_, newValue int) {
+		select {
+		case changes <- newValue:
+		default:
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErrCh := make(chan error, 1)
+
+	go func() {
+		runErrCh <- w.Run(ctx)
+	}()
+
+	// Give the watcher time to start
+	time.Sleep(100 * time.Millisecond)
+
+	require.NoError(t, os.WriteFile(path, []byte("rate_limit: 20\n"), 0o600))
+
+	select {
+	case limit := <-changes:
+		assert.Equal(t, 20, limit)
+	case <-time.After(5 * time.Second):
+		t.Error("configuration change was not delivered")
+	}
+
+	cancel()
+
+	select {
+	case err := <-runErrCh:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Error("watcher did not stop")
+	}
+}
+
+func TestWatcherReloadOverlay(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"config.yaml":            "rate_limit: 10\n",
+		"config.production.yaml": "rate_limit: 20\n",
+	})
+
+	opts := []Option{WithConfigPaths(dir), WithOverlays("production")}
+
+	v := viper.New()
+	require.NoError(t, AutoConfigure(watchTestConfigs, v, opts...))
+	assert.Equal(t, 20, v.GetInt("rate_limit"))
+
+	w, err := newTestWatcher(watchTestConfigs, v, opts...)
+	require.NoError(t, err)
+
+	writeFiles(t, dir, map[string]string{"config.production.yaml": "rate_limit: 30\n"})
+	require.NoError(t, w.Reload())
+	assert.Equal(t, 30, v.GetInt("rate_limit"))
+}
@@ -0,0 +1,19 @@
+package httphandlers
+
+import (
+	"net/http"
+
+	"github.com/adroit-group/gote/pkg/config"
+	"github.com/adroit-group/gote/pkg/httputils"
+)
+
+// ResolvedConfigProvider is a function that returns the resolved configuration values of the application.
+type ResolvedConfigProvider func() []config.ResolvedValue
+
+// NewConfigHandlerFunc creates a new HTTP handler function that returns every registered configuration option
+// with its effective value and source. The secret values are redacted by config.Inspect.
+func NewConfigHandlerFunc(provider ResolvedConfigProvider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		httputils.WriteJSONResponse(w, http.StatusOK, provider())
+	}
+}
@@ -0,0 +1,36 @@
+package httphandlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/adroit-group/gote/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConfigHandlerFunc(t *testing.T) {
+	handler := NewConfigHandlerFunc(func() []config.ResolvedValue {
+		return []config.ResolvedValue{
+			{Key: "http.port", Value: 8080, Source: config.SourceEnv},
+			{Key: "db.password", Value: config.Redacted, Source: config.SourceFile},
+		}
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var responseBody []map[string]any
+	err := json.Unmarshal(rec.Body.Bytes(), &responseBody)
+	require.NoError(t, err)
+	require.Equal(t, []map[string]any{
+		{"key": "http.port", "value": float64(8080), "source": "env"},
+		{"key": "db.password", "value": "[REDACTED]", "source": "file"},
+	}, responseBody)
+}
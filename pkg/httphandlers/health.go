@@ -0,0 +1,342 @@
+package httphandlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// DefaultCheckTimeout is the timeout of the checks registered without WithCheckTimeout.
+const DefaultCheckTimeout = 5 * time.Second
+
+// HealthStatus is the status of a check, or the aggregate status of the service.
+type HealthStatus string
+
+const (
+	// HealthStatusUp means the check passed, or every check passed.
+	HealthStatusUp HealthStatus = "up"
+	// HealthStatusDegraded means only non-critical checks failed, the service can still serve traffic.
+	HealthStatusDegraded HealthStatus = "degraded"
+	// HealthStatusDown means the check failed, or at least one critical check failed.
+	HealthStatusDown HealthStatus = "down"
+	// HealthStatusShuttingDown means the service is draining before the shutdown, and must not receive new requests.
+	HealthStatusShuttingDown HealthStatus = "shutting_down"
+)
+
+// Checker checks the health of a component, e.g. the connection to a database.
+type Checker interface {
+	// Check returns an error if the component is not healthy.
+	// The context is canceled when the timeout of the check expires.
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc is an adapter to allow the use of ordinary functions as checkers.
+type CheckerFunc func(ctx context.Context) error
+
+// Check calls f(ctx).
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// CheckOption configures a check registered in a HealthRegistry.
+type CheckOption func(*check)
+
+// WithCheckTimeout sets the timeout of the check, DefaultCheckTimeout by default.
+// The check fails if it does not return in time.
+func WithCheckTimeout(timeout time.Duration) CheckOption {
+	return func(c *check) {
+		c.timeout = timeout
+	}
+}
+
+// WithNonCritical marks the check as non-critical, its failure degrades the service, but does not make it unready.
+// Checks are critical by default.
+func WithNonCritical() CheckOption {
+	return func(c *check) {
+		c.critical = false
+	}
+}
+
+// WithCacheTTL caches the result of the check for the ttl, to protect the checked component from frequent probes.
+// Results are not cached by default.
+func WithCacheTTL(ttl time.Duration) CheckOption {
+	return func(c *check) {
+		c.cacheTTL = ttl
+	}
+}
+
+// WithLiveness includes the check in the liveness probe as well.
+// Only the checks detecting that the process cannot recover without a restart,
+// e.g. a deadlock, should be included, the failure of a dependency must not restart the service.
+func WithLiveness() CheckOption {
+	return func(c *check) {
+		c.liveness = true
+	}
+}
+
+// CheckResult is the result of a single check.
+type CheckResult struct {
+	Name      string        `json:"name"`
+	Status    HealthStatus  `json:"status"`
+	Critical  bool          `json:"critical"`
+	Latency   time.Duration `json:"-"`
+	Error     string        `json:"error,omitempty"`
+	CheckedAt time.Time     `json:"checked_at"`
+}
+
+// MarshalJSON encodes the latency in a human-readable form, e.g. "1.5ms".
+func (r CheckResult) MarshalJSON() ([]byte, error) {
+	type result CheckResult
+
+	return json.Marshal(struct {
+		result
+		Latency string `json:"latency"`
+	}{
+		result:  result(r),
+		Latency: r.Latency.String(),
+	})
+}
+
+// HealthReport is the aggregate result of the checks.
+type HealthReport struct {
+	Status HealthStatus  `json:"status"`
+	Checks []CheckResult `json:"checks,omitempty"`
+}
+
+// check is a checker registered in a HealthRegistry.
+type check struct {
+	name     string
+	checker  Checker
+	timeout  time.Duration
+	critical bool
+	cacheTTL time.Duration
+	liveness bool
+
+	mu     sync.Mutex
+	cached *CheckResult
+	flight singleflight.Group
+}
+
+// HealthRegistry holds the named checkers of the components of the service.
+// It is safe for concurrent use.
+type HealthRegistry struct {
+	mu       sync.RWMutex
+	checks   []*check
+	draining atomic.Bool
+}
+
+// NewHealthRegistry creates an empty HealthRegistry.
+func NewHealthRegistry() *HealthRegistry {
+	return &HealthRegistry{}
+}
+
+// Register registers a named checker, replacing the checker registered with the same name.
+func (h *HealthRegistry) Register(name string, checker Checker, opts ...CheckOption) {
+	c := &check{
+		name:     name,
+		checker:  checker,
+		timeout:  DefaultCheckTimeout,
+		critical: true,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i, registered := range h.checks {
+		if registered.name == name {
+			h.checks[i] = c
+
+			return
+		}
+	}
+
+	h.checks = append(h.checks, c)
+}
+
+// Live runs the checks included in the liveness probe, see WithLiveness.
+func (h *HealthRegistry) Live(ctx context.Context) HealthReport {
+	return h.run(ctx, func(c *check) bool { return c.liveness })
+}
+
+// Ready runs every registered check.
+// Once the registry is draining, it reports HealthStatusShuttingDown without running the checks.
+func (h *HealthRegistry) Ready(ctx context.Context) HealthReport {
+	if h.Draining() {
+		return HealthReport{Status: HealthStatusShuttingDown}
+	}
+
+	return h.run(ctx, func(*check) bool { return true })
+}
+
+// Health runs every registered check, even when the registry is draining, for the detailed health report.
+// Once the registry is draining, it reports HealthStatusShuttingDown with the results of the checks.
+func (h *HealthRegistry) Health(ctx context.Context) HealthReport {
+	report := h.run(ctx, func(*check) bool { return true })
+	if h.Draining() {
+		report.Status = HealthStatusShuttingDown
+	}
+
+	return report
+}
+
+// Drain marks the service as shutting down, failing the readiness probe, while the liveness probe keeps passing.
+// It implements infra.Drainer.
+func (h *HealthRegistry) Drain() {
+	h.draining.Store(true)
+}
+
+// Draining reports whether Drain was called.
+func (h *HealthRegistry) Draining() bool {
+	return h.draining.Load()
+}
+
+// run runs the selected checks concurrently, and aggregates their results in the order of the registration.
+func (h *HealthRegistry) run(ctx context.Context, selected func(*check) bool) HealthReport {
+	h.mu.RLock()
+
+	var checks []*check
+
+	for _, c := range h.checks {
+		if selected(c) {
+			checks = append(checks, c)
+		}
+	}
+
+	h.mu.RUnlock()
+
+	results := make([]CheckResult, len(checks))
+
+	var wg sync.WaitGroup
+
+	for i, c := range checks {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			results[i] = c.run(ctx)
+		}()
+	}
+
+	wg.Wait()
+
+	return HealthReport{Status: aggregateStatus(results), Checks: results}
+}
+
+// aggregateStatus returns the status of the service based on the results of the checks.
+func aggregateStatus(results []CheckResult) HealthStatus {
+	status := HealthStatusUp
+
+	for _, result := range results {
+		if result.Status == HealthStatusUp {
+			continue
+		}
+
+		if result.Critical {
+			return HealthStatusDown
+		}
+
+		status = HealthStatusDegraded
+	}
+
+	return status
+}
+
+// run runs the check, or returns the cached result if it is still fresh.
+// The lock is only held to read and store the cached result, so the concurrent probes do not wait for each other,
+// and the concurrent cache misses share a single run of the checker.
+func (c *check) run(ctx context.Context) CheckResult {
+	if c.cacheTTL <= 0 {
+		return c.runChecker(ctx)
+	}
+
+	if result, ok := c.cachedResult(); ok {
+		return result
+	}
+
+	result, _, _ := c.flight.Do(c.name, func() (any, error) {
+		if result, ok := c.cachedResult(); ok {
+			return result, nil
+		}
+
+		// The shared run is not canceled when the caller starting it goes away, it is limited by the timeout
+		result := c.runChecker(context.WithoutCancel(ctx))
+
+		c.mu.Lock()
+		c.cached = &result
+		c.mu.Unlock()
+
+		return result, nil
+	})
+
+	checkResult, _ := result.(CheckResult)
+
+	return checkResult
+}
+
+// cachedResult returns the cached result, if it is still fresh.
+func (c *check) cachedResult() (CheckResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cached != nil && time.Since(c.cached.CheckedAt) < c.cacheTTL {
+		return *c.cached, true
+	}
+
+	return CheckResult{}, false
+}
+
+// runChecker runs the checker, and returns its result.
+func (c *check) runChecker(ctx context.Context) CheckResult {
+	start := time.Now()
+	err := c.checkWithTimeout(ctx)
+
+	result := CheckResult{
+		Name:      c.name,
+		Status:    HealthStatusUp,
+		Critical:  c.critical,
+		Latency:   time.Since(start),
+		CheckedAt: start,
+	}
+
+	if err != nil {
+		result.Status = HealthStatusDown
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// checkWithTimeout runs the checker, and fails when the timeout expires, even if the checker ignores the context.
+func (c *check) checkWithTimeout(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("check panicked: %v", r)
+			}
+		}()
+
+		done <- c.checker.Check(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("check did not complete within %s: %w", c.timeout, ctx.Err())
+	}
+}
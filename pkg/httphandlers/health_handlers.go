@@ -0,0 +1,46 @@
+package httphandlers
+
+import (
+	"net/http"
+
+	"github.com/adroit-group/gote/pkg/httputils"
+)
+
+// NewLivenessHandlerFunc creates a new HTTP handler function for the liveness probe.
+// It runs only the checks registered with WithLiveness, and responds with the aggregate status.
+func NewLivenessHandlerFunc(registry *HealthRegistry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := registry.Live(r.Context())
+		httputils.WriteJSONResponse(w, healthStatusCode(report), HealthReport{Status: report.Status})
+	}
+}
+
+// NewReadinessHandlerFunc creates a new HTTP handler function for the readiness probe.
+// It runs every registered check, and responds with the aggregate status.
+func NewReadinessHandlerFunc(registry *HealthRegistry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := registry.Ready(r.Context())
+		httputils.WriteJSONResponse(w, healthStatusCode(report), HealthReport{Status: report.Status})
+	}
+}
+
+// NewHealthHandlerFunc creates a new HTTP handler function that runs every registered check,
+// and responds with the aggregate status, and the status, latency and error of every check.
+// The checks are run while the service is shutting down as well, see HealthRegistry.Health.
+func NewHealthHandlerFunc(registry *HealthRegistry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := registry.Health(r.Context())
+		httputils.WriteJSONResponse(w, healthStatusCode(report), report)
+	}
+}
+
+// healthStatusCode returns 503 Service Unavailable if the service is down or shutting down, and 200 OK otherwise,
+// so a degraded service still receives traffic.
+func healthStatusCode(report HealthReport) int {
+	switch report.Status {
+	case HealthStatusDown, HealthStatusShuttingDown:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusOK
+	}
+}
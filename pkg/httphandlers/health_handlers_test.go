@@ -0,0 +1,122 @@
+package httphandlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthHandlers(t *testing.T) {
+	healthy := NewHealthRegistry()
+	healthy.Register("database", CheckerFunc(passing))
+
+	degraded := NewHealthRegistry()
+	degraded.Register("cache", CheckerFunc(failing), WithNonCritical())
+
+	unhealthy := NewHealthRegistry()
+	unhealthy.Register("database", CheckerFunc(failing))
+
+	draining := NewHealthRegistry()
+	draining.Register("database", CheckerFunc(passing))
+	draining.Drain()
+
+	testCases := []struct {
+		name           string
+		handler        http.HandlerFunc
+		expectedCode   int
+		expectedStatus string
+		expectedChecks int
+	}{
+		{name: "live", handler: NewLivenessHandlerFunc(unhealthy), expectedCode: http.StatusOK, expectedStatus: "up"},
+		{name: "ready", handler: NewReadinessHandlerFunc(healthy), expectedCode: http.StatusOK, expectedStatus: "up"},
+		{name: "ready degraded", handler: NewReadinessHandlerFunc(degraded), expectedCode: http.StatusOK, expectedStatus: "degraded"},
+		{
+			name:           "not ready",
+			handler:        NewReadinessHandlerFunc(unhealthy),
+			expectedCode:   http.StatusServiceUnavailable,
+			expectedStatus: "down",
+		},
+		{
+			name:           "draining",
+			handler:        NewReadinessHandlerFunc(draining),
+			expectedCode:   http.StatusServiceUnavailable,
+			expectedStatus: "shutting_down",
+		},
+		{name: "live while draining", handler: NewLivenessHandlerFunc(draining), expectedCode: http.StatusOK, expectedStatus: "up"},
+		{
+			name:           "health",
+			handler:        NewHealthHandlerFunc(healthy),
+			expectedCode:   http.StatusOK,
+			expectedStatus: "up",
+			expectedChecks: 1,
+		},
+		{
+			name:           "health while draining",
+			handler:        NewHealthHandlerFunc(draining),
+			expectedCode:   http.StatusServiceUnavailable,
+			expectedStatus: "shutting_down",
+			expectedChecks: 1,
+		},
+		{
+			name:           "unhealthy",
+			handler:        NewHealthHandlerFunc(unhealthy),
+			expectedCode:   http.StatusServiceUnavailable,
+			expectedStatus: "down",
+			expectedChecks: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			rec := httptest.NewRecorder()
+
+			tc.handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedCode, rec.Code)
+
+			var responseBody struct {
+				Status string           `json:"status"`
+				Checks []map[string]any `json:"checks"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &responseBody))
+			assert.Equal(t, tc.expectedStatus, responseBody.Status)
+			assert.Len(t, responseBody.Checks, tc.expectedChecks)
+		})
+	}
+}
+
+func TestHealthHandlerCheckDetails(t *testing.T) {
+	registry := NewHealthRegistry()
+	registry.Register("database", CheckerFunc(failing))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+
+	NewHealthHandlerFunc(registry).ServeHTTP(rec, req)
+
+	var responseBody struct {
+		Checks []struct {
+			Name      string `json:"name"`
+			Status    string `json:"status"`
+			Critical  bool   `json:"critical"`
+			Error     string `json:"error"`
+			Latency   string `json:"latency"`
+			CheckedAt string `json:"checked_at"`
+		} `json:"checks"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &responseBody))
+	require.Len(t, responseBody.Checks, 1)
+
+	check := responseBody.Checks[0]
+	assert.Equal(t, "database", check.Name)
+	assert.Equal(t, "down", check.Status)
+	assert.True(t, check.Critical)
+	assert.Equal(t, errUnavailable.Error(), check.Error)
+	assert.NotEmpty(t, check.Latency)
+	assert.NotEmpty(t, check.CheckedAt)
+}
@@ -0,0 +1,224 @@
+package httphandlers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errUnavailable = errors.New("connection refused")
+
+func passing(context.Context) error { return nil }
+
+func failing(context.Context) error { return errUnavailable }
+
+func TestHealthRegistryReady(t *testing.T) {
+	testCases := []struct {
+		name             string
+		register         func(h *HealthRegistry)
+		expectedStatus   HealthStatus
+		expectedStatuses []HealthStatus
+	}{
+		{
+			name:           "no checks",
+			register:       func(*HealthRegistry) {},
+			expectedStatus: HealthStatusUp,
+		},
+		{
+			name: "every check passes",
+			register: func(h *HealthRegistry) {
+				h.Register("database", CheckerFunc(passing))
+				h.Register("cache", CheckerFunc(passing), WithNonCritical())
+			},
+			expectedStatus:   HealthStatusUp,
+			expectedStatuses: []HealthStatus{HealthStatusUp, HealthStatusUp},
+		},
+		{
+			name: "non-critical check fails",
+			register: func(h *HealthRegistry) {
+				h.Register("database", CheckerFunc(passing))
+				h.Register("cache", CheckerFunc(failing), WithNonCritical())
+			},
+			expectedStatus:   HealthStatusDegraded,
+			expectedStatuses: []HealthStatus{HealthStatusUp, HealthStatusDown},
+		},
+		{
+			name: "critical check fails",
+			register: func(h *HealthRegistry) {
+				h.Register("database", CheckerFunc(failing))
+				h.Register("cache", CheckerFunc(failing), WithNonCritical())
+			},
+			expectedStatus:   HealthStatusDown,
+			expectedStatuses: []HealthStatus{HealthStatusDown, HealthStatusDown},
+		},
+		{
+			name: "check times out",
+			register: func(h *HealthRegistry) {
+				h.Register("database", CheckerFunc(func(context.Context) error {
+					time.Sleep(time.Second)
+
+					return nil
+				}), WithCheckTimeout(10*time.Millisecond))
+			},
+			expectedStatus:   HealthStatusDown,
+			expectedStatuses: []HealthStatus{HealthStatusDown},
+		},
+		{
+			name: "check panics",
+			register: func(h *HealthRegistry) {
+				h.Register("database", CheckerFunc(func(context.Context) error {
+					panic("nil pointer")
+				}))
+			},
+			expectedStatus:   HealthStatusDown,
+			expectedStatuses: []HealthStatus{HealthStatusDown},
+		},
+		{
+			name: "re-registered check replaces the previous one",
+			register: func(h *HealthRegistry) {
+				h.Register("database", CheckerFunc(failing))
+				h.Register("database", CheckerFunc(passing))
+			},
+			expectedStatus:   HealthStatusUp,
+			expectedStatuses: []HealthStatus{HealthStatusUp},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := NewHealthRegistry()
+			tc.register(h)
+
+			report := h.Ready(context.Background())
+			assert.Equal(t, tc.expectedStatus, report.Status)
+
+			var statuses []HealthStatus
+			for _, result := range report.Checks {
+				statuses = append(statuses, result.Status)
+			}
+
+			assert.Equal(t, tc.expectedStatuses, statuses)
+		})
+	}
+}
+
+func TestHealthRegistryCheckResult(t *testing.T) {
+	h := NewHealthRegistry()
+	h.Register("database", CheckerFunc(failing), WithNonCritical())
+
+	report := h.Ready(context.Background())
+	require.Len(t, report.Checks, 1)
+
+	result := report.Checks[0]
+	assert.Equal(t, "database", result.Name)
+	assert.False(t, result.Critical)
+	assert.Equal(t, errUnavailable.Error(), result.Error)
+	assert.False(t, result.CheckedAt.IsZero())
+}
+
+func TestHealthRegistryLive(t *testing.T) {
+	h := NewHealthRegistry()
+	h.Register("database", CheckerFunc(failing))
+	h.Register("deadlock", CheckerFunc(passing), WithLiveness())
+
+	report := h.Live(context.Background())
+	assert.Equal(t, HealthStatusUp, report.Status)
+	require.Len(t, report.Checks, 1)
+	assert.Equal(t, "deadlock", report.Checks[0].Name)
+}
+
+func TestHealthRegistryCache(t *testing.T) {
+	var calls atomic.Int32
+
+	h := NewHealthRegistry()
+	h.Register("database", CheckerFunc(func(context.Context) error {
+		calls.Add(1)
+
+		return nil
+	}), WithCacheTTL(time.Hour))
+	h.Register("cache", CheckerFunc(func(context.Context) error {
+		calls.Add(1)
+
+		return nil
+	}))
+
+	h.Ready(context.Background())
+	h.Ready(context.Background())
+
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestHealthRegistryConcurrentProbes(t *testing.T) {
+	const (
+		probes  = 4
+		latency = 300 * time.Millisecond
+	)
+
+	testCases := []struct {
+		name          string
+		opts          []CheckOption
+		expectedCalls int32
+	}{
+		{name: "without cache", expectedCalls: probes},
+		{name: "with cache", opts: []CheckOption{WithCacheTTL(time.Hour)}, expectedCalls: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls atomic.Int32
+
+			h := NewHealthRegistry()
+			h.Register("database", CheckerFunc(func(context.Context) error {
+				calls.Add(1)
+				time.Sleep(latency)
+
+				return nil
+			}), tc.opts...)
+
+			start := time.Now()
+
+			var wg sync.WaitGroup
+			for range probes {
+				wg.Add(1)
+
+				go func() {
+					defer wg.Done()
+
+					assert.Equal(t, HealthStatusUp, h.Ready(context.Background()).Status)
+				}()
+			}
+
+			wg.Wait()
+
+			// The probes do not wait for each other
+			assert.Less(t, time.Since(start), 2*latency)
+			assert.Equal(t, tc.expectedCalls, calls.Load())
+		})
+	}
+}
+
+func TestHealthRegistryDrain(t *testing.T) {
+	h := NewHealthRegistry()
+	h.Register("database", CheckerFunc(passing))
+	h.Register("deadlock", CheckerFunc(passing), WithLiveness())
+
+	assert.False(t, h.Draining())
+	assert.Equal(t, HealthStatusUp, h.Ready(context.Background()).Status)
+
+	h.Drain()
+
+	assert.True(t, h.Draining())
+	assert.Equal(t, HealthReport{Status: HealthStatusShuttingDown}, h.Ready(context.Background()))
+	assert.Equal(t, HealthStatusUp, h.Live(context.Background()).Status)
+
+	report := h.Health(context.Background())
+	assert.Equal(t, HealthStatusShuttingDown, report.Status)
+	require.Len(t, report.Checks, 2)
+	assert.Equal(t, HealthStatusUp, report.Checks[0].Status)
+}
@@ -0,0 +1,51 @@
+package httphandlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/adroit-group/gote/pkg/httputils"
+)
+
+// LogLevel is the body of the requests and the responses of the log level endpoint.
+type LogLevel struct {
+	Level string `json:"level"`
+}
+
+// NewLogLevelHandlerFunc creates a new HTTP handler function that returns the current log level on GET,
+// and changes it at runtime on PUT, with a body like {"level": "debug"}.
+// The level is one of debug, info, warn and error, with an optional offset, e.g. "info+2", see slog.Level.
+func NewLogLevelHandlerFunc(level *slog.LevelVar) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var request LogLevel
+			if err := httputils.ReadJSONRequest(r, &request); err != nil {
+				httputils.WriteError(w, r, err)
+
+				return
+			}
+
+			var newLevel slog.Level
+			if err := newLevel.UnmarshalText([]byte(request.Level)); err != nil {
+				httputils.WriteError(w, r, httputils.NewProblem(http.StatusBadRequest, err.Error()))
+
+				return
+			}
+
+			if oldLevel := level.Level(); oldLevel != newLevel {
+				// Logged before the change, so it is not filtered out by a higher level
+				slog.InfoContext(r.Context(), "changing log level", "from", oldLevel.String(), "to", newLevel.String())
+				level.Set(newLevel)
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			httputils.WriteError(w, r, httputils.NewProblem(http.StatusMethodNotAllowed, ""))
+
+			return
+		}
+
+		httputils.WriteJSONResponse(w, http.StatusOK, LogLevel{Level: level.Level().String()})
+	}
+}
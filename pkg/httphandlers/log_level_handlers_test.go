@@ -0,0 +1,52 @@
+package httphandlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLogLevelHandlerFunc(t *testing.T) {
+	testCases := []struct {
+		name          string
+		method        string
+		body          string
+		expectedCode  int
+		expectedLevel slog.Level
+	}{
+		{name: "get", method: http.MethodGet, expectedCode: http.StatusOK, expectedLevel: slog.LevelInfo},
+		{name: "set", method: http.MethodPut, body: `{"level": "debug"}`, expectedCode: http.StatusOK, expectedLevel: slog.LevelDebug},
+		{name: "set with offset", method: http.MethodPut, body: `{"level": "WARN+1"}`, expectedCode: http.StatusOK, expectedLevel: slog.LevelWarn + 1},
+		{name: "invalid level", method: http.MethodPut, body: `{"level": "verbose"}`, expectedCode: http.StatusBadRequest, expectedLevel: slog.LevelInfo},
+		{name: "invalid body", method: http.MethodPut, body: `{`, expectedCode: http.StatusBadRequest, expectedLevel: slog.LevelInfo},
+		{name: "unsupported method", method: http.MethodDelete, expectedCode: http.StatusMethodNotAllowed, expectedLevel: slog.LevelInfo},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			level := &slog.LevelVar{}
+			handler := NewLogLevelHandlerFunc(level)
+
+			req := httptest.NewRequest(tc.method, "/", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedCode, rec.Code)
+			assert.Equal(t, tc.expectedLevel, level.Level())
+
+			if tc.expectedCode == http.StatusOK {
+				var response LogLevel
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+				assert.Equal(t, tc.expectedLevel.String(), response.Level)
+			}
+		})
+	}
+}
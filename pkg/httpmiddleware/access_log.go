@@ -0,0 +1,43 @@
+package httpmiddleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/adroit-group/gote/pkg/logger"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// AccessLog logs every request once it is served, with its status, size and duration,
+// using the request logger of the context, see RequestLogger.
+// The requests failing with a server error are logged at the error level, the rest at the info level.
+func AccessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		defer func() {
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			level := slog.LevelInfo
+			if status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+
+			logger.FromContext(r.Context()).Log(r.Context(), level, "request served",
+				"path", r.URL.Path,
+				"status", status,
+				"bytes", ww.BytesWritten(),
+				"duration", time.Since(start),
+				"remote_addr", r.RemoteAddr,
+				"user_agent", r.UserAgent(),
+			)
+		}()
+
+		next.ServeHTTP(ww, r)
+	})
+}
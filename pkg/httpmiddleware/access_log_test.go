@@ -0,0 +1,62 @@
+package httpmiddleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessLog(t *testing.T) {
+	testCases := []struct {
+		name          string
+		status        int
+		expectedLevel string
+	}{
+		{name: "success", status: http.StatusCreated, expectedLevel: "INFO"},
+		{name: "client error", status: http.StatusNotFound, expectedLevel: "INFO"},
+		{name: "server error", status: http.StatusBadGateway, expectedLevel: "ERROR"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			r := chi.NewRouter()
+			r.Use(RequestID, RequestLogger(slog.New(slog.NewJSONHandler(&buf, nil))), AccessLog)
+			r.Post("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte("body"))
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/users/1?page=2", nil)
+			req.Header.Set(RequestIDHeader, "request-1")
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			var entry struct {
+				Level     string `json:"level"`
+				Method    string `json:"method"`
+				Path      string `json:"path"`
+				Route     string `json:"route"`
+				Status    int    `json:"status"`
+				Bytes     int    `json:"bytes"`
+				RequestID string `json:"request_id"`
+			}
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+
+			assert.Equal(t, tc.expectedLevel, entry.Level)
+			assert.Equal(t, http.MethodPost, entry.Method)
+			assert.Equal(t, "/users/1", entry.Path)
+			assert.Equal(t, "/users/{id}", entry.Route)
+			assert.Equal(t, tc.status, entry.Status)
+			assert.Equal(t, 4, entry.Bytes)
+			assert.Equal(t, "request-1", entry.RequestID)
+		})
+	}
+}
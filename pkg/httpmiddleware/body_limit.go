@@ -0,0 +1,36 @@
+package httpmiddleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
+type bodyLimitKey struct{}
+
+// BodyLimit limits the size of the request bodies to maxBytes, reading more fails with an *http.MaxBytesError.
+//
+// BodyLimit used again, e.g. in a route group, replaces the limit of the outer one, zero disables it.
+func BodyLimit(maxBytes int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// The original body is kept in the context, so the nested limits replace the outer ones
+			ctx := r.Context()
+
+			body, ok := ctx.Value(bodyLimitKey{}).(io.ReadCloser)
+			if !ok {
+				body = r.Body
+				ctx = context.WithValue(ctx, bodyLimitKey{}, body)
+			}
+
+			// The body is replaced in a shallow copy, so the request of the outer handlers keeps its own body
+			r = r.WithContext(ctx)
+			r.Body = body
+			if maxBytes > 0 && body != nil {
+				r.Body = http.MaxBytesReader(w, body, maxBytes)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
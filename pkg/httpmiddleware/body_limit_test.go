@@ -0,0 +1,60 @@
+package httpmiddleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func readBody(w http.ResponseWriter, r *http.Request) {
+	if _, err := io.ReadAll(r.Body); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestBodyLimit(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(BodyLimit(10))
+	r.Post("/", readBody)
+	r.With(BodyLimit(100)).Post("/upload", readBody)
+	r.With(BodyLimit(0)).Post("/unlimited", readBody)
+
+	testCases := []struct {
+		name         string
+		path         string
+		size         int
+		expectedCode int
+	}{
+		{name: "within the limit", path: "/", size: 10, expectedCode: http.StatusOK},
+		{name: "over the limit", path: "/", size: 11, expectedCode: http.StatusRequestEntityTooLarge},
+		{name: "raised limit", path: "/upload", size: 100, expectedCode: http.StatusOK},
+		{name: "over the raised limit", path: "/upload", size: 101, expectedCode: http.StatusRequestEntityTooLarge},
+		{name: "disabled limit", path: "/unlimited", size: 1000, expectedCode: http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(strings.Repeat("a", tc.size))))
+
+			assert.Equal(t, tc.expectedCode, rec.Code)
+		})
+	}
+}
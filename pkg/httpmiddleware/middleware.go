@@ -0,0 +1,82 @@
+package httpmiddleware
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultRequestTimeout is the request timeout of DefaultConfig.
+	DefaultRequestTimeout = 10 * time.Second
+	// DefaultMaxBodyBytes is the maximum request body size of DefaultConfig, 1 MiB.
+	DefaultMaxBodyBytes = 1 << 20
+)
+
+// Config configures the default middleware stack, see Default.
+type Config struct {
+	// Logger is the base of the request loggers, slog.Default() if nil, see RequestLogger.
+	Logger *slog.Logger
+	// RequestTimeout is the timeout of the requests, zero disables it.
+	RequestTimeout time.Duration
+	// MaxBodyBytes is the maximum size of the request bodies, zero disables the limit.
+	MaxBodyBytes int64
+	// TrustedProxies are the addresses of the proxies whose forwarding headers are trusted to find the client IP.
+	TrustedProxies []netip.Prefix
+}
+
+// DefaultConfig returns the configuration of the default middleware stack with the default limits.
+func DefaultConfig() Config {
+	return Config{
+		RequestTimeout: DefaultRequestTimeout,
+		MaxBodyBytes:   DefaultMaxBodyBytes,
+	}
+}
+
+// Default returns the default middleware stack, in the order they should be used:
+// the request ID, the client IP, the request logger, the access log, the panic recovery, the request timeout
+// and the body size limit.
+//
+// The stack can be extended by appending middlewares to it, or per route group with chi.Router.Use.
+// Timeout and BodyLimit used again in a route group replace the limits of the stack for its routes.
+func Default(c Config) []func(http.Handler) http.Handler {
+	return []func(http.Handler) http.Handler{
+		RequestID,
+		RealIP(c.TrustedProxies...),
+		RequestLogger(c.Logger),
+		AccessLog,
+		Recoverer,
+		Timeout(c.RequestTimeout),
+		BodyLimit(c.MaxBodyBytes),
+	}
+}
+
+// ParseTrustedProxies parses the IP addresses and CIDR ranges of the trusted proxies.
+func ParseTrustedProxies(proxies []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(proxies))
+
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			addr, err := netip.ParseAddr(proxy)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+			}
+
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+
+		prefixes = append(prefixes, prefix.Masked())
+	}
+
+	return prefixes, nil
+}
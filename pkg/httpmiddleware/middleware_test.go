@@ -0,0 +1,52 @@
+package httpmiddleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefault(t *testing.T) {
+	var buf bytes.Buffer
+
+	config := DefaultConfig()
+	config.Logger = slog.New(slog.NewJSONHandler(&buf, nil))
+
+	r := chi.NewRouter()
+	r.Use(Default(config)...)
+	r.Get("/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get(RequestIDHeader))
+
+	decoder := json.NewDecoder(&buf)
+
+	// The panic and the request are logged with the request logger
+	var entry struct {
+		Msg       string `json:"msg"`
+		Status    int    `json:"status"`
+		Route     string `json:"route"`
+		RequestID string `json:"request_id"`
+	}
+	require.NoError(t, decoder.Decode(&entry))
+	assert.Equal(t, "panic recovered", entry.Msg)
+	assert.Equal(t, "/panic", entry.Route)
+	assert.Equal(t, rec.Header().Get(RequestIDHeader), entry.RequestID)
+
+	require.NoError(t, decoder.Decode(&entry))
+	assert.Equal(t, "request served", entry.Msg)
+	assert.Equal(t, http.StatusInternalServerError, entry.Status)
+	assert.Equal(t, rec.Header().Get(RequestIDHeader), entry.RequestID)
+}
@@ -0,0 +1,89 @@
+package httpmiddleware
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+const (
+	// ForwardedForHeader is the header listing the addresses of the client and the proxies the request passed through.
+	ForwardedForHeader = "X-Forwarded-For"
+	// RealIPHeader is the header carrying the client address, set by some proxies instead of ForwardedForHeader.
+	RealIPHeader = "X-Real-Ip"
+)
+
+// RealIP sets the RemoteAddr of the requests to the client address found in the forwarding headers,
+// if the request is sent by one of the trusted proxies. The headers of other peers are ignored,
+// as they can be forged by the clients.
+//
+// The client is the rightmost address of ForwardedForHeader not belonging to a trusted proxy,
+// or the address of RealIPHeader. It is a no-op without trusted proxies.
+func RealIP(trustedProxies ...netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if len(trustedProxies) == 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ip, ok := clientIP(r, trustedProxies); ok {
+				r.RemoteAddr = ip.String()
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientIP returns the address of the client, if the request is sent by a trusted proxy.
+func clientIP(r *http.Request, trustedProxies []netip.Prefix) (netip.Addr, bool) {
+	peer, ok := parseAddr(r.RemoteAddr)
+	if !ok || !trusted(peer, trustedProxies) {
+		return netip.Addr{}, false
+	}
+
+	if forwardedFor := r.Header.Values(ForwardedForHeader); len(forwardedFor) > 0 {
+		hops := strings.Split(strings.Join(forwardedFor, ","), ",")
+
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop, ok := parseAddr(strings.TrimSpace(hops[i]))
+			if !ok {
+				// The rest of the chain cannot be trusted
+				return netip.Addr{}, false
+			}
+
+			if !trusted(hop, trustedProxies) {
+				return hop, true
+			}
+		}
+
+		return netip.Addr{}, false
+	}
+
+	return parseAddr(strings.TrimSpace(r.Header.Get(RealIPHeader)))
+}
+
+// parseAddr parses an IP address, with or without a port.
+func parseAddr(s string) (netip.Addr, bool) {
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+
+	return addr.Unmap(), true
+}
+
+func trusted(addr netip.Addr, trustedProxies []netip.Prefix) bool {
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
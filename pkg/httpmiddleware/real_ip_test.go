@@ -0,0 +1,100 @@
+package httpmiddleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRealIP(t *testing.T) {
+	trustedProxies, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1"})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor []string
+		realIP       string
+		expected     string
+	}{
+		{
+			name:         "untrusted peer",
+			remoteAddr:   "203.0.113.1:1234",
+			forwardedFor: []string{"198.51.100.1"},
+			expected:     "203.0.113.1:1234",
+		},
+		{
+			name:         "trusted proxy",
+			remoteAddr:   "10.0.0.1:1234",
+			forwardedFor: []string{"198.51.100.1"},
+			expected:     "198.51.100.1",
+		},
+		{
+			name:         "chain of trusted proxies",
+			remoteAddr:   "10.0.0.1:1234",
+			forwardedFor: []string{"203.0.113.7, 198.51.100.1", "192.168.1.1"},
+			expected:     "198.51.100.1",
+		},
+		{
+			name:         "forged hop",
+			remoteAddr:   "10.0.0.1:1234",
+			forwardedFor: []string{"198.51.100.1, invalid"},
+			expected:     "10.0.0.1:1234",
+		},
+		{
+			name:       "real IP header",
+			remoteAddr: "192.168.1.1:1234",
+			realIP:     "2001:db8::1",
+			expected:   "2001:db8::1",
+		},
+		{
+			name:       "no forwarding headers",
+			remoteAddr: "10.0.0.1:1234",
+			expected:   "10.0.0.1:1234",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var remoteAddr string
+
+			handler := RealIP(trustedProxies...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				remoteAddr = r.RemoteAddr
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tc.remoteAddr
+
+			for _, value := range tc.forwardedFor {
+				req.Header.Add(ForwardedForHeader, value)
+			}
+
+			if tc.realIP != "" {
+				req.Header.Set(RealIPHeader, tc.realIP)
+			}
+
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tc.expected, remoteAddr)
+		})
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	prefixes, err := ParseTrustedProxies([]string{"10.1.2.3/8", "::ffff:192.168.1.1", "2001:db8::/32"})
+	require.NoError(t, err)
+
+	expected := []string{"10.0.0.0/8", "192.168.1.1/32", "2001:db8::/32"}
+	for i, prefix := range prefixes {
+		assert.Equal(t, expected[i], prefix.String())
+	}
+
+	_, err = ParseTrustedProxies([]string{"invalid"})
+	require.Error(t, err)
+
+	_, err = ParseTrustedProxies([]string{"10.0.0.0/33"})
+	require.Error(t, err)
+}
@@ -0,0 +1,47 @@
+package httpmiddleware
+
+import (
+	"errors"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/adroit-group/gote/pkg/httputils"
+	"github.com/adroit-group/gote/pkg/logger"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Recoverer recovers the panics of the handlers, logs them with the stack trace using the request logger,
+// and responds with a 500 Internal Server Error httputils.Problem, if the response is not written yet.
+//
+// http.ErrAbortHandler is panicked again, so the server aborts the response as intended.
+func Recoverer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+
+			if err, ok := rec.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(rec)
+			}
+
+			logger.FromContext(r.Context()).ErrorContext(r.Context(), "panic recovered",
+				"panic", rec,
+				"stack", string(debug.Stack()),
+			)
+
+			if ww.Status() == 0 {
+				httputils.WriteProblem(ww, &httputils.Problem{
+					Status:    http.StatusInternalServerError,
+					Instance:  r.URL.Path,
+					RequestID: httputils.RequestIDFromContext(r.Context()),
+				})
+			}
+		}()
+
+		next.ServeHTTP(ww, r)
+	})
+}
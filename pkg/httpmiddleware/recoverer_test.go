@@ -0,0 +1,66 @@
+package httpmiddleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/adroit-group/gote/pkg/httputils"
+	"github.com/adroit-group/gote/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoverer(t *testing.T) {
+	logger.SetupSlog("test", io.Discard)
+
+	t.Run("panic", func(t *testing.T) {
+		handler := RequestID(Recoverer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		})))
+
+		req := httptest.NewRequest(http.MethodGet, "/users", nil)
+		req.Header.Set(RequestIDHeader, "request-1")
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Equal(t, httputils.ProblemContentType, rec.Header().Get("Content-Type"))
+
+		var response httputils.Problem
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, httputils.Problem{
+			Type:      httputils.DefaultProblemType,
+			Title:     "Internal Server Error",
+			Status:    http.StatusInternalServerError,
+			Instance:  "/users",
+			RequestID: "request-1",
+		}, response)
+	})
+
+	t.Run("panic after the response is written", func(t *testing.T) {
+		handler := Recoverer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			panic("boom")
+		}))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		assert.Empty(t, rec.Body.String())
+	})
+
+	t.Run("abort handler", func(t *testing.T) {
+		handler := Recoverer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic(http.ErrAbortHandler)
+		}))
+
+		assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		})
+	})
+}
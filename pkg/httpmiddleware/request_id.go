@@ -0,0 +1,53 @@
+package httpmiddleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/adroit-group/gote/pkg/httputils"
+)
+
+// RequestIDHeader is the header carrying the request ID, both in the requests and the responses.
+const RequestIDHeader = "X-Request-Id"
+
+// maxRequestIDLength is the maximum length of the request IDs accepted from the clients.
+const maxRequestIDLength = 128
+
+// RequestID propagates the request ID of the RequestIDHeader, or generates a new one if it is missing or invalid.
+// The ID is set on the response header, and can be read with httputils.RequestIDFromContext.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+
+		next.ServeHTTP(w, r.WithContext(httputils.ContextWithRequestID(r.Context(), id)))
+	})
+}
+
+// validRequestID reports whether the request ID sent by the client is safe to log and echo back.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, c := range id {
+		// Printable ASCII without spaces
+		if c <= ' ' || c > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b) // never returns an error
+
+	return hex.EncodeToString(b)
+}
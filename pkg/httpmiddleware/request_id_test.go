@@ -0,0 +1,49 @@
+package httpmiddleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/adroit-group/gote/pkg/httputils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	testCases := []struct {
+		name      string
+		header    string
+		propagate bool
+	}{
+		{name: "propagated", header: "abc-123", propagate: true},
+		{name: "missing", header: ""},
+		{name: "too long", header: strings.Repeat("a", maxRequestIDLength+1)},
+		{name: "control characters", header: "abc\x01"},
+		{name: "spaces", header: "abc def"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var fromContext string
+
+			handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fromContext = httputils.RequestIDFromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(RequestIDHeader, tc.header)
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, fromContext, rec.Header().Get(RequestIDHeader))
+
+			if tc.propagate {
+				assert.Equal(t, tc.header, fromContext)
+			} else {
+				assert.Len(t, fromContext, 32)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package httpmiddleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/adroit-group/gote/pkg/httputils"
+	"github.com/adroit-group/gote/pkg/logger"
+	"github.com/go-chi/chi/v5"
+)
+
+// RequestLogger attaches a logger to the context of the requests, see logger.FromContext,
+// logging the request ID, the method, the chi route pattern, and the trace and parent span IDs
+// of the TraceparentHeader with every log line, so they can be correlated.
+// The route is only logged once the request is routed.
+//
+// The request logger is derived from base, or slog.Default() if it is nil.
+// It should be used after RequestID.
+func RequestLogger(base *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			l := base
+			if l == nil {
+				l = slog.Default()
+			}
+
+			if rctx := chi.RouteContext(r.Context()); rctx != nil {
+				l = slog.New(&routeHandler{Handler: l.Handler(), rctx: rctx})
+			}
+
+			attrs := []any{"method", r.Method}
+			if id := httputils.RequestIDFromContext(r.Context()); id != "" {
+				attrs = append(attrs, "request_id", id)
+			}
+
+			if traceID, parentSpanID, ok := ParseTraceparent(r.Header.Get(TraceparentHeader)); ok {
+				attrs = append(attrs, "trace_id", traceID, "parent_span_id", parentSpanID)
+			}
+
+			ctx := logger.NewContext(r.Context(), l.With(attrs...))
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// routeHandler adds the chi route pattern to the records. The pattern is only known once the request is routed,
+// after the middlewares of the router created the request logger.
+type routeHandler struct {
+	slog.Handler
+	rctx *chi.Context
+}
+
+func (h *routeHandler) Handle(ctx context.Context, r slog.Record) error {
+	if pattern := h.rctx.RoutePattern(); pattern != "" {
+		r = r.Clone()
+		r.AddAttrs(slog.String("route", pattern))
+	}
+
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *routeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &routeHandler{Handler: h.Handler.WithAttrs(attrs), rctx: h.rctx}
+}
+
+// WithGroup adds the route before opening the group, so it is not logged as a member of the group.
+// The route is not logged if the group is opened before the request is routed.
+func (h *routeHandler) WithGroup(name string) slog.Handler {
+	handler := h.Handler
+	if pattern := h.rctx.RoutePattern(); pattern != "" {
+		handler = handler.WithAttrs([]slog.Attr{slog.String("route", pattern)})
+	}
+
+	return handler.WithGroup(name)
+}
@@ -0,0 +1,69 @@
+package httpmiddleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/adroit-group/gote/pkg/logger"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestLogger(t *testing.T) {
+	var buf bytes.Buffer
+
+	r := chi.NewRouter()
+	r.Use(RequestID, RequestLogger(slog.New(slog.NewJSONHandler(&buf, nil))))
+	r.Route("/api", func(r chi.Router) {
+		r.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+			ctx := logger.WithAttrs(r.Context(), "user_id", chi.URLParam(r, "id"))
+			logger.FromContext(ctx).InfoContext(ctx, "user loaded")
+			logger.FromContext(ctx).WithGroup("cache").InfoContext(ctx, "cache missed", "key", "user")
+		})
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/users/1", nil)
+	req.Header.Set(RequestIDHeader, "request-1")
+	req.Header.Set(TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	decoder := json.NewDecoder(&buf)
+
+	var entry map[string]any
+	require.NoError(t, decoder.Decode(&entry))
+	assert.Equal(t, "user loaded", entry["msg"])
+	assert.Equal(t, "request-1", entry["request_id"])
+	assert.Equal(t, http.MethodGet, entry["method"])
+	assert.Equal(t, "/api/users/{id}", entry["route"])
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", entry["trace_id"])
+	assert.Equal(t, "00f067aa0ba902b7", entry["parent_span_id"])
+	assert.NotContains(t, entry, "span_id")
+	assert.Equal(t, "1", entry["user_id"])
+
+	// The route is not logged in the group
+	entry = nil
+	require.NoError(t, decoder.Decode(&entry))
+	assert.Equal(t, "/api/users/{id}", entry["route"])
+	assert.Equal(t, map[string]any{"key": "user"}, entry["cache"])
+}
+
+func TestRequestLoggerWithoutRouter(t *testing.T) {
+	var buf bytes.Buffer
+
+	handler := RequestLogger(slog.New(slog.NewJSONHandler(&buf, nil)))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.FromContext(r.Context()).InfoContext(r.Context(), "hello")
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, http.MethodGet, entry["method"])
+	assert.NotContains(t, entry, "route")
+	assert.NotContains(t, entry, "request_id")
+	assert.NotContains(t, entry, "trace_id")
+}
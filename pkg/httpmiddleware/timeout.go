@@ -0,0 +1,74 @@
+package httpmiddleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/adroit-group/gote/pkg/httputils"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+type timeoutKey struct{}
+
+// timeoutState is shared by the Timeout middlewares of a request, so the innermost one replaces the outer ones.
+type timeoutState struct {
+	// parent is the request context before the outermost Timeout.
+	parent context.Context
+	// ctx is the context with the effective timeout.
+	ctx context.Context
+}
+
+// Timeout cancels the context of the requests after the timeout, and responds with a 503 Service Unavailable
+// httputils.Problem, if the handler returned without writing the response.
+// The handlers must respect the context, the timeout does not interrupt them.
+//
+// Timeout used again, e.g. in a route group, replaces the timeout of the outer one, zero disables it.
+func Timeout(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if state, ok := r.Context().Value(timeoutKey{}).(*timeoutState); ok {
+				ctx, cancel := withTimeout(state.parent, timeout)
+				defer cancel()
+
+				state.ctx = ctx
+
+				next.ServeHTTP(w, r.WithContext(valuesContext{Context: ctx, values: r.Context()}))
+
+				return
+			}
+
+			ctx, cancel := withTimeout(r.Context(), timeout)
+			defer cancel()
+
+			state := &timeoutState{parent: r.Context(), ctx: ctx}
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			next.ServeHTTP(ww, r.WithContext(context.WithValue(ctx, timeoutKey{}, state)))
+
+			if errors.Is(state.ctx.Err(), context.DeadlineExceeded) && ww.Status() == 0 {
+				httputils.WriteError(ww, r, context.DeadlineExceeded)
+			}
+		})
+	}
+}
+
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+// valuesContext has the deadline and the cancellation of the embedded context, but the values of another one,
+// so the values added to the request context between the nested Timeout middlewares are kept.
+type valuesContext struct {
+	context.Context
+	values context.Context
+}
+
+func (c valuesContext) Value(key any) any {
+	return c.values.Value(key)
+}
@@ -0,0 +1,69 @@
+package httpmiddleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+type testKey struct{}
+
+// waitForContext blocks until the request context is canceled, or the wait expires.
+func waitForContext(wait time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(wait):
+			w.WriteHeader(http.StatusOK)
+		}
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(Timeout(20 * time.Millisecond))
+	r.Get("/slow", waitForContext(time.Second))
+	r.Get("/fast", waitForContext(0))
+	r.Group(func(r chi.Router) {
+		r.Use(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), testKey{}, "value")))
+			})
+		})
+		r.Use(Timeout(200 * time.Millisecond))
+		r.Get("/longer", func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "value", r.Context().Value(testKey{}))
+			waitForContext(50*time.Millisecond)(w, r)
+		})
+		r.Get("/longer/slow", waitForContext(time.Second))
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(Timeout(0))
+		r.Get("/disabled", waitForContext(50*time.Millisecond))
+	})
+
+	testCases := []struct {
+		path         string
+		expectedCode int
+	}{
+		{path: "/slow", expectedCode: http.StatusServiceUnavailable},
+		{path: "/fast", expectedCode: http.StatusOK},
+		{path: "/longer", expectedCode: http.StatusOK},
+		{path: "/longer/slow", expectedCode: http.StatusServiceUnavailable},
+		{path: "/disabled", expectedCode: http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			assert.Equal(t, tc.expectedCode, rec.Code)
+		})
+	}
+}
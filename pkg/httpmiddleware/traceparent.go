@@ -0,0 +1,45 @@
+package httpmiddleware
+
+import (
+	"encoding/hex"
+	"strings"
+)
+
+// TraceparentHeader is the W3C Trace Context header carrying the trace ID and the span ID of the caller.
+const TraceparentHeader = "Traceparent"
+
+// ParseTraceparent parses the trace ID and the parent span ID of a W3C traceparent header,
+// e.g. "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01". It returns false if the header is invalid.
+func ParseTraceparent(header string) (string, string, bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 {
+		return "", "", false
+	}
+
+	version, traceID, spanID, flags := parts[0], parts[1], parts[2], parts[3]
+
+	// Version 00 has exactly four parts, the future versions may append more
+	if !isLowerHex(version, 2) || version == "ff" || (version == "00" && len(parts) != 4) {
+		return "", "", false
+	}
+
+	if !isLowerHex(traceID, 32) || !isLowerHex(spanID, 16) || !isLowerHex(flags, 2) {
+		return "", "", false
+	}
+
+	if strings.Trim(traceID, "0") == "" || strings.Trim(spanID, "0") == "" {
+		return "", "", false
+	}
+
+	return traceID, spanID, true
+}
+
+func isLowerHex(s string, length int) bool {
+	if len(s) != length || strings.ToLower(s) != s {
+		return false
+	}
+
+	_, err := hex.DecodeString(s)
+
+	return err == nil
+}
@@ -0,0 +1,333 @@
+package httputils
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+// The struct tags naming the request parameters bound to the fields, see BindRequest.
+const (
+	TagPath   = "path"
+	TagQuery  = "query"
+	TagHeader = "header"
+)
+
+// paramTags are the tags of the request parameters in the order they are looked up.
+var paramTags = []string{TagPath, TagQuery, TagHeader}
+
+var (
+	durationType        = reflect.TypeFor[time.Duration]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// errUnsupportedParamType is returned when the type of a field cannot be bound to a request parameter.
+var errUnsupportedParamType = errors.New("unsupported parameter type")
+
+// ErrInvalidParam is returned when a request parameter cannot be converted to the type of its field.
+var ErrInvalidParam = errors.New("invalid parameter")
+
+// ParamError is returned by BindRequest when a parameter cannot be converted to the type of its field.
+// It matches ErrInvalidParam and the error of the conversion.
+type ParamError struct {
+	// In is the location of the parameter, TagPath, TagQuery or TagHeader.
+	In string
+	// Name is the name of the parameter, e.g. "page".
+	Name string
+	// Value is the invalid value.
+	Value string
+	// Type is the Go type of the field, e.g. "int".
+	Type string
+	// Err is the error of the conversion.
+	Err error
+}
+
+func (e *ParamError) Error() string {
+	return fmt.Sprintf("%s: %s parameter %q must be %s, got %q", ErrInvalidParam, e.In, e.Name, e.Type, e.Value)
+}
+
+func (e *ParamError) Unwrap() []error {
+	return []error{ErrInvalidParam, e.Err}
+}
+
+// requestParam is a field of the bound struct populated from a request parameter.
+type requestParam struct {
+	in        string
+	name      string
+	index     []int
+	namespace string
+}
+
+// BindRequest populates t from the request, and validates it using the provided validator:
+//   - the fields tagged with `path:"name"` are set from the chi URL parameters,
+//   - the fields tagged with `query:"name"` from the query string,
+//   - the fields tagged with `header:"Name"` from the request headers,
+//   - and the rest of the fields are decoded from the JSON body with ReadJSONRequest, when the request has a body.
+//     The body cannot set the parameter fields, even if they have no `json:"-"` tag.
+//
+// The parameters are converted to strings, bools, ints, uints, floats, time.Duration, types implementing
+// encoding.TextUnmarshaler, e.g. time.Time as RFC 3339, and the slices and pointers of them. The slices are set from
+// the repeated parameters, or from comma separated values. Enums are named types validated with the oneof rule.
+// The missing or empty parameters leave the fields unchanged, so the defaults can be set in t before binding.
+//
+// It returns a *ParamError if a parameter cannot be converted, the errors of ReadJSONRequest,
+// or a *ValidationError if the validation fails, where the parameters are named as in the request.
+func BindRequest[T any](r *http.Request, v *validator.Validate, t *T) error {
+	params, hasBody := requestFields(reflect.TypeFor[T]())
+
+	if hasBody && r.Body != nil && r.Body != http.NoBody && r.ContentLength != 0 {
+		if err := readJSONBody(r, t, params); err != nil {
+			return err
+		}
+	}
+
+	value := reflect.ValueOf(t).Elem()
+	for _, param := range params {
+		if err := bindParam(value.FieldByIndex(param.index), param, paramValues(r, param)); err != nil {
+			return err
+		}
+	}
+
+	err := validationError(r, v, v.Struct(t))
+
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		nameParams(validationErr, params)
+	}
+
+	return err
+}
+
+// readJSONBody decodes the body into t, except the parameter fields, so the body cannot set the values
+// that must come from the path, the query or the headers, e.g. the identity headers set by a gateway.
+func readJSONBody[T any](r *http.Request, t *T, params []requestParam) error {
+	decoded := *t
+
+	value := reflect.ValueOf(&decoded).Elem()
+	for _, param := range params {
+		value.FieldByIndex(param.index).SetZero()
+	}
+
+	if err := ReadJSONRequest(r, &decoded); err != nil && !errors.Is(err, ErrEmptyJSONBody) {
+		return err
+	}
+
+	original := reflect.ValueOf(t).Elem()
+	for _, param := range params {
+		value.FieldByIndex(param.index).Set(original.FieldByIndex(param.index))
+	}
+
+	*t = decoded
+
+	return nil
+}
+
+// requestFields returns the fields of the struct bound to the request parameters,
+// and whether it has fields decoded from the body.
+func requestFields(t reflect.Type) ([]requestParam, bool) {
+	if t.Kind() != reflect.Struct {
+		return nil, false
+	}
+
+	var params []requestParam
+
+	hasBody := collectRequestFields(t, nil, t.Name(), &params)
+
+	return params, hasBody
+}
+
+func collectRequestFields(t reflect.Type, index []int, namespace string, params *[]requestParam) bool {
+	hasBody := false
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+
+		fieldIndex := append(append([]int{}, index...), i)
+		fieldNamespace := namespace + "." + field.Name
+
+		param, ok := paramOf(field)
+
+		switch {
+		case ok && field.IsExported():
+			param.index = fieldIndex
+			param.namespace = fieldNamespace
+			*params = append(*params, param)
+		case field.Anonymous && field.Type.Kind() == reflect.Struct && field.Tag.Get("json") == "":
+			hasBody = collectRequestFields(field.Type, fieldIndex, fieldNamespace, params) || hasBody
+		case field.IsExported() && field.Tag.Get("json") != "-":
+			hasBody = true
+		}
+	}
+
+	return hasBody
+}
+
+func paramOf(field reflect.StructField) (requestParam, bool) {
+	for _, tag := range paramTags {
+		if name, ok := field.Tag.Lookup(tag); ok && name != "" && name != "-" {
+			return requestParam{in: tag, name: name}, true
+		}
+	}
+
+	return requestParam{}, false
+}
+
+func paramValues(r *http.Request, param requestParam) []string {
+	switch param.in {
+	case TagPath:
+		if value := chi.URLParam(r, param.name); value != "" {
+			return []string{value}
+		}
+
+		return nil
+	case TagQuery:
+		return r.URL.Query()[param.name]
+	default:
+		return r.Header.Values(param.name)
+	}
+}
+
+// bindParam sets the field from the values of the parameter, the empty values are ignored.
+func bindParam(field reflect.Value, param requestParam, values []string) error {
+	values = nonEmpty(values)
+	if len(values) == 0 {
+		return nil
+	}
+
+	fieldType := field.Type()
+	if fieldType.Kind() == reflect.Pointer {
+		fieldType = fieldType.Elem()
+	}
+
+	target := reflect.New(fieldType).Elem()
+
+	if fieldType.Kind() == reflect.Slice && !fieldType.Implements(textUnmarshalerType) &&
+		!reflect.PointerTo(fieldType).Implements(textUnmarshalerType) {
+		var elements []string
+		for _, value := range values {
+			elements = append(elements, nonEmpty(strings.Split(value, ","))...)
+		}
+
+		target.Set(reflect.MakeSlice(fieldType, len(elements), len(elements)))
+
+		for i, element := range elements {
+			if err := setValue(target.Index(i), param, strings.TrimSpace(element)); err != nil {
+				return err
+			}
+		}
+	} else if err := setValue(target, param, values[0]); err != nil {
+		return err
+	}
+
+	if field.Kind() == reflect.Pointer {
+		field.Set(target.Addr())
+	} else {
+		field.Set(target)
+	}
+
+	return nil
+}
+
+func nonEmpty(values []string) []string {
+	result := make([]string, 0, len(values))
+
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			result = append(result, value)
+		}
+	}
+
+	return result
+}
+
+// setValue converts the value to the type of the settable target.
+func setValue(target reflect.Value, param requestParam, value string) error {
+	err := convertValue(target, value)
+	if err == nil || errors.Is(err, errUnsupportedParamType) {
+		return err
+	}
+
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		err = numErr.Err
+	}
+
+	return &ParamError{In: param.in, Name: param.name, Value: value, Type: target.Type().String(), Err: err}
+}
+
+func convertValue(target reflect.Value, value string) error {
+	if unmarshaler, ok := target.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(value))
+	}
+
+	if target.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+
+		target.SetInt(int64(d))
+
+		return nil
+	}
+
+	switch target.Kind() {
+	case reflect.String:
+		target.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+
+		target.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, target.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		target.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 10, target.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		target.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, target.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		target.SetFloat(f)
+	default:
+		return fmt.Errorf("%w: %s", errUnsupportedParamType, target.Type())
+	}
+
+	return nil
+}
+
+// nameParams names the invalid parameters as in the request, they have no JSON path.
+func nameParams(validationErr *ValidationError, params []requestParam) {
+	for i, fieldErr := range validationErr.errs {
+		for _, param := range params {
+			if fieldErr.StructNamespace() == param.namespace {
+				validationErr.Fields[i].Field = param.name
+				validationErr.Fields[i].JSONPath = ""
+			}
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package httputils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type bindTestOrder string
+
+type bindTestPagination struct {
+	Page  int `query:"page" validate:"gte=1"`
+	Limit int `query:"limit" validate:"lte=100"`
+}
+
+type bindTestRequest struct {
+	bindTestPagination
+
+	ID       uint64        `path:"id"`
+	Tenant   string        `header:"X-Tenant-Id" validate:"required"`
+	Tags     []string      `query:"tag"`
+	IDs      []int         `query:"ids"`
+	Since    *time.Time    `query:"since"`
+	Timeout  time.Duration `query:"timeout"`
+	Order    bindTestOrder `query:"order" validate:"omitempty,oneof=asc desc"`
+	Verbose  bool          `query:"verbose"`
+	Ratio    float64       `query:"ratio"`
+	Name     string        `json:"name" validate:"required"`
+	internal string
+}
+
+// bind serves the request with a chi router, so the path parameters are set.
+func bind[T any](t *testing.T, req *http.Request, data *T) error {
+	t.Helper()
+
+	var err error
+
+	r := chi.NewRouter()
+	r.HandleFunc("/users/{id}", func(_ http.ResponseWriter, r *http.Request) {
+		err = BindRequest(r, NewValidator(), data)
+	})
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	return err
+}
+
+func TestBindRequest(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost,
+		"/users/42?page=2&tag=a&tag=b,c&ids=1,%202&since=2024-01-02T03:04:05Z&timeout=1m30s&order=desc&verbose=true&ratio=0.5",
+		strings.NewReader(`{"name":"John"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Tenant-Id", "acme")
+
+	data := bindTestRequest{bindTestPagination: bindTestPagination{Limit: 20}}
+	require.NoError(t, bind(t, req, &data))
+
+	since := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.Equal(t, bindTestRequest{
+		bindTestPagination: bindTestPagination{Page: 2, Limit: 20},
+		ID:                 42,
+		Tenant:             "acme",
+		Tags:               []string{"a", "b", "c"},
+		IDs:                []int{1, 2},
+		Since:              &since,
+		Timeout:            90 * time.Second,
+		Order:              "desc",
+		Verbose:            true,
+		Ratio:              0.5,
+		Name:               "John",
+	}, data)
+}
+
+func TestBindRequestWithoutBody(t *testing.T) {
+	type listRequest struct {
+		bindTestPagination
+
+		Query string `query:"q"`
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1?page=1&q=john", nil)
+
+	var data listRequest
+	require.NoError(t, bind(t, req, &data))
+	assert.Equal(t, listRequest{bindTestPagination: bindTestPagination{Page: 1}, Query: "john"}, data)
+}
+
+func TestBindRequestBodyCannotSetParams(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/users/42",
+		strings.NewReader(`{"name":"John","Tenant":"victim","Page":5,"ID":1,"Limit":1000}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	data := bindTestRequest{bindTestPagination: bindTestPagination{Limit: 20}}
+	err := bind(t, req, &data)
+
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []FieldError{
+		{Field: "page", Rule: "gte", Param: "1", Message: "page must be 1 or greater"},
+		{Field: "X-Tenant-Id", Rule: "required", Message: "X-Tenant-Id is a required field"},
+	}, validationErr.Fields)
+	assert.Equal(t, bindTestRequest{bindTestPagination: bindTestPagination{Limit: 20}, ID: 42, Name: "John"}, data)
+}
+
+func TestBindRequestErrors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		target string
+		body   string
+		check  func(t *testing.T, err error)
+	}{
+		{
+			name:   "invalid int",
+			target: "/users/42?page=first",
+			body:   `{"name":"John"}`,
+			check: func(t *testing.T, err error) {
+				var paramErr *ParamError
+				require.ErrorAs(t, err, &paramErr)
+				require.ErrorIs(t, err, ErrInvalidParam)
+				assert.Equal(t, ParamError{In: TagQuery, Name: "page", Value: "first", Type: "int", Err: paramErr.Err}, *paramErr)
+				assert.EqualError(t, err, `invalid parameter: query parameter "page" must be int, got "first"`)
+			},
+		},
+		{
+			name:   "invalid path parameter",
+			target: "/users/-1?page=1",
+			body:   `{"name":"John"}`,
+			check: func(t *testing.T, err error) {
+				var paramErr *ParamError
+				require.ErrorAs(t, err, &paramErr)
+				assert.Equal(t, TagPath, paramErr.In)
+				assert.Equal(t, "uint64", paramErr.Type)
+			},
+		},
+		{
+			name:   "invalid slice element",
+			target: "/users/42?page=1&ids=1,x",
+			body:   `{"name":"John"}`,
+			check: func(t *testing.T, err error) {
+				var paramErr *ParamError
+				require.ErrorAs(t, err, &paramErr)
+				assert.Equal(t, "x", paramErr.Value)
+				assert.Equal(t, "int", paramErr.Type)
+			},
+		},
+		{
+			name:   "invalid time",
+			target: "/users/42?page=1&since=yesterday",
+			body:   `{"name":"John"}`,
+			check: func(t *testing.T, err error) {
+				var paramErr *ParamError
+				require.ErrorAs(t, err, &paramErr)
+				assert.Equal(t, "time.Time", paramErr.Type)
+			},
+		},
+		{
+			name:   "invalid body",
+			target: "/users/42?page=1",
+			body:   `{"name":`,
+			check: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrInvalidJSONBody)
+			},
+		},
+		{
+			name:   "validation",
+			target: "/users/42?page=0&order=random",
+			body:   `{}`,
+			check: func(t *testing.T, err error) {
+				var validationErr *ValidationError
+				require.ErrorAs(t, err, &validationErr)
+				assert.Equal(t, []FieldError{
+					{Field: "page", Rule: "gte", Param: "1", Message: "page must be 1 or greater"},
+					{Field: "X-Tenant-Id", Rule: "required", Message: "X-Tenant-Id is a required field"},
+					{Field: "order", Rule: "oneof", Param: "asc desc", Message: "order must be one of [asc desc]"},
+					{Field: "name", JSONPath: "$.name", Rule: "required", Message: "name is a required field"},
+				}, validationErr.Fields)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tc.target, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			var data bindTestRequest
+			tc.check(t, bind(t, req, &data))
+		})
+	}
+}
+
+func TestBindRequestUnsupportedType(t *testing.T) {
+	type request struct {
+		Filter map[string]string `query:"filter"`
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1?filter=a", nil)
+
+	var data request
+	err := bind(t, req, &data)
+	require.ErrorIs(t, err, errUnsupportedParamType)
+	assert.NotErrorIs(t, err, ErrInvalidParam)
+}
@@ -0,0 +1,288 @@
+package httputils
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// DefaultMaxJSONBodyBytes is the default maximum size of the JSON request bodies, see WithMaxBytes.
+const DefaultMaxJSONBodyBytes = 1 << 20
+
+// DefaultJSONMediaTypes are the media types accepted by default, application/json and the types with the +json suffix,
+// e.g. application/merge-patch+json.
+var DefaultJSONMediaTypes = []string{"application/json", "application/*+json"}
+
+// DefaultJSONDecoder is the decoder used by ReadJSONRequest.
+var DefaultJSONDecoder = NewJSONDecoder()
+
+var (
+	// ErrEmptyJSONBody is returned when the request has no body.
+	ErrEmptyJSONBody = fmt.Errorf("%w: the body is empty", ErrInvalidJSONBody)
+	// ErrTrailingJSONData is returned when the body has more data after the JSON value.
+	ErrTrailingJSONData = fmt.Errorf("%w: the body must contain a single JSON value", ErrInvalidJSONBody)
+)
+
+// UnsupportedMediaTypeError is returned when the media type of the request is not accepted by the decoder,
+// or its charset is not UTF-8. It matches ErrInvalidContentType.
+type UnsupportedMediaTypeError struct {
+	// ContentType is the Content-Type header of the request.
+	ContentType string
+	// Supported are the media types accepted by the decoder.
+	Supported []string
+}
+
+func (e *UnsupportedMediaTypeError) Error() string {
+	return fmt.Sprintf("%s: %q, supported: %s", ErrInvalidContentType, e.ContentType, strings.Join(e.Supported, ", "))
+}
+
+func (e *UnsupportedMediaTypeError) Unwrap() error {
+	return ErrInvalidContentType
+}
+
+// UnsupportedEncodingError is returned when the content encoding of the request is not supported by the decoder.
+// It matches ErrInvalidContentType.
+type UnsupportedEncodingError struct {
+	// Encoding is the Content-Encoding header of the request.
+	Encoding string
+}
+
+func (e *UnsupportedEncodingError) Error() string {
+	return fmt.Sprintf("%s: unsupported content encoding %q", ErrInvalidContentType, e.Encoding)
+}
+
+func (e *UnsupportedEncodingError) Unwrap() error {
+	return ErrInvalidContentType
+}
+
+// JSONSyntaxError is returned when the body is not valid JSON, or it is not a valid gzip stream.
+// It matches ErrInvalidJSONBody and the error of the decoder, e.g. *json.SyntaxError.
+type JSONSyntaxError struct {
+	// Offset is the number of bytes read before the error, if known.
+	Offset int64
+	// Err is the error of the decoder.
+	Err error
+}
+
+func (e *JSONSyntaxError) Error() string {
+	return fmt.Sprintf("%s at offset %d: %s", ErrInvalidJSONBody, e.Offset, e.Err)
+}
+
+func (e *JSONSyntaxError) Unwrap() []error {
+	return []error{ErrInvalidJSONBody, e.Err}
+}
+
+// JSONFieldTypeError is returned when a JSON value cannot be decoded into the type of its field.
+// It matches ErrInvalidJSONBody.
+type JSONFieldTypeError struct {
+	// Field is the path of the field, e.g. "address.city".
+	Field string
+	// Value is the type of the JSON value, e.g. "string".
+	Value string
+	// Type is the expected Go type of the field, e.g. "int".
+	Type string
+}
+
+func (e *JSONFieldTypeError) Error() string {
+	return fmt.Sprintf("%s: field %q must be %s, got %s", ErrInvalidJSONBody, e.Field, e.Type, e.Value)
+}
+
+func (e *JSONFieldTypeError) Unwrap() error {
+	return ErrInvalidJSONBody
+}
+
+// UnknownJSONFieldError is returned when the body has a field unknown to the decoded type,
+// see WithDisallowUnknownFields. It matches ErrInvalidJSONBody.
+type UnknownJSONFieldError struct {
+	// Field is the name of the unknown field.
+	Field string
+}
+
+func (e *UnknownJSONFieldError) Error() string {
+	return fmt.Sprintf("%s: unknown field %q", ErrInvalidJSONBody, e.Field)
+}
+
+func (e *UnknownJSONFieldError) Unwrap() error {
+	return ErrInvalidJSONBody
+}
+
+// JSONDecoder decodes the JSON request bodies, see NewJSONDecoder.
+type JSONDecoder struct {
+	mediaTypes            []string
+	maxBytes              int64
+	disallowUnknownFields bool
+	gzip                  bool
+}
+
+// JSONDecoderOption configures a JSONDecoder.
+type JSONDecoderOption func(*JSONDecoder)
+
+// WithMediaTypes sets the accepted media types, a "*" matches any part of the type, e.g. "application/*+json".
+// The default is DefaultJSONMediaTypes.
+func WithMediaTypes(mediaTypes ...string) JSONDecoderOption {
+	return func(d *JSONDecoder) {
+		d.mediaTypes = mediaTypes
+	}
+}
+
+// WithMaxBytes sets the maximum size of the body, larger bodies fail with *http.MaxBytesError.
+// The limit applies to the decompressed body as well. Zero disables the limit.
+// The default is DefaultMaxJSONBodyBytes.
+func WithMaxBytes(maxBytes int64) JSONDecoderOption {
+	return func(d *JSONDecoder) {
+		d.maxBytes = maxBytes
+	}
+}
+
+// WithDisallowUnknownFields makes the fields unknown to the decoded type fail with *UnknownJSONFieldError.
+func WithDisallowUnknownFields() JSONDecoderOption {
+	return func(d *JSONDecoder) {
+		d.disallowUnknownFields = true
+	}
+}
+
+// WithGzip accepts the gzip compressed bodies, see the Content-Encoding header.
+func WithGzip() JSONDecoderOption {
+	return func(d *JSONDecoder) {
+		d.gzip = true
+	}
+}
+
+// NewJSONDecoder creates a decoder with the options. By default, it accepts the DefaultJSONMediaTypes,
+// limits the body to DefaultMaxJSONBodyBytes, allows the unknown fields and rejects the compressed bodies.
+// The body must contain a single JSON value.
+func NewJSONDecoder(opts ...JSONDecoderOption) *JSONDecoder {
+	d := &JSONDecoder{
+		mediaTypes: DefaultJSONMediaTypes,
+		maxBytes:   DefaultMaxJSONBodyBytes,
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+// Decode checks the headers of the request, and decodes its body into v.
+func (d *JSONDecoder) Decode(r *http.Request, v any) error {
+	if err := d.checkContentType(r.Header.Get("Content-Type")); err != nil {
+		return err
+	}
+
+	body, err := d.body(r)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	decoder := json.NewDecoder(body)
+	if d.disallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+
+	if err := decoder.Decode(v); err != nil {
+		return decodeError(decoder, err)
+	}
+
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return err
+		}
+
+		return ErrTrailingJSONData
+	}
+
+	return nil
+}
+
+func (d *JSONDecoder) checkContentType(contentType string) error {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err == nil && (params["charset"] == "" || strings.EqualFold(params["charset"], "utf-8")) {
+		for _, supported := range d.mediaTypes {
+			if matchMediaType(supported, mediaType) {
+				return nil
+			}
+		}
+	}
+
+	return &UnsupportedMediaTypeError{ContentType: contentType, Supported: d.mediaTypes}
+}
+
+// matchMediaType reports whether the media type matches the pattern, a "*" in the pattern matches any characters.
+func matchMediaType(pattern, mediaType string) bool {
+	prefix, suffix, wildcard := strings.Cut(pattern, "*")
+	if !wildcard {
+		return pattern == mediaType
+	}
+
+	return len(mediaType) > len(prefix)+len(suffix) && strings.HasPrefix(mediaType, prefix) && strings.HasSuffix(mediaType, suffix)
+}
+
+// body returns the body of the request limited to the maximum size, decompressed if needed.
+func (d *JSONDecoder) body(r *http.Request) (io.ReadCloser, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, ErrEmptyJSONBody
+	}
+
+	body := d.limit(r.Body)
+
+	switch encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))); {
+	case encoding == "" || encoding == "identity":
+		return body, nil
+	case encoding == "gzip" && d.gzip:
+		gzipReader, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, decodeError(nil, err)
+		}
+
+		return d.limit(gzipReader), nil
+	default:
+		return nil, &UnsupportedEncodingError{Encoding: r.Header.Get("Content-Encoding")}
+	}
+}
+
+func (d *JSONDecoder) limit(body io.ReadCloser) io.ReadCloser {
+	if d.maxBytes <= 0 {
+		return body
+	}
+
+	return http.MaxBytesReader(nil, body, d.maxBytes)
+}
+
+// decodeError converts the errors of the JSON and gzip decoders to the typed errors of the decoder.
+func decodeError(decoder *json.Decoder, err error) error {
+	var (
+		maxBytesErr *http.MaxBytesError
+		syntaxErr   *json.SyntaxError
+		typeErr     *json.UnmarshalTypeError
+	)
+
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return err
+	case errors.Is(err, io.EOF):
+		return ErrEmptyJSONBody
+	case errors.As(err, &syntaxErr):
+		return &JSONSyntaxError{Offset: syntaxErr.Offset, Err: err}
+	case errors.As(err, &typeErr):
+		return &JSONFieldTypeError{Field: typeErr.Field, Value: typeErr.Value, Type: typeErr.Type.String()}
+	}
+
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return &UnknownJSONFieldError{Field: strings.Trim(field, `"`)}
+	}
+
+	var offset int64
+	if decoder != nil {
+		offset = decoder.InputOffset()
+	}
+
+	return &JSONSyntaxError{Offset: offset, Err: err}
+}
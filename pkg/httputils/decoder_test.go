@@ -0,0 +1,305 @@
+package httputils
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type decoderTestData struct {
+	Name    string `json:"name"`
+	Address struct {
+		Zip int `json:"zip"`
+	} `json:"address"`
+}
+
+func gzipBody(t *testing.T, body string) io.Reader {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write([]byte(body))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	return &buf
+}
+
+func TestJSONDecoderContentType(t *testing.T) {
+	testCases := []struct {
+		name        string
+		contentType string
+		mediaTypes  []string
+		expectedErr bool
+	}{
+		{name: "json", contentType: "application/json"},
+		{name: "charset", contentType: "application/json; charset=UTF-8"},
+		{name: "suffix", contentType: "application/merge-patch+json"},
+		{name: "case insensitive", contentType: "Application/JSON"},
+		{name: "other charset", contentType: "application/json; charset=iso-8859-1", expectedErr: true},
+		{name: "other type", contentType: "text/plain", expectedErr: true},
+		{name: "suffix only", contentType: "application/+json", expectedErr: true},
+		{name: "missing", contentType: "", expectedErr: true},
+		{name: "malformed", contentType: "application/", expectedErr: true},
+		{
+			name:        "custom type",
+			contentType: "application/vnd.example+json",
+			mediaTypes:  []string{"application/vnd.example+json"},
+		},
+		{name: "custom type rejects json", contentType: "application/json", mediaTypes: []string{"application/vnd.example+json"}, expectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var opts []JSONDecoderOption
+			if tc.mediaTypes != nil {
+				opts = append(opts, WithMediaTypes(tc.mediaTypes...))
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"John"}`))
+			req.Header.Set("Content-Type", tc.contentType)
+
+			var data decoderTestData
+			err := NewJSONDecoder(opts...).Decode(req, &data)
+
+			if !tc.expectedErr {
+				require.NoError(t, err)
+				assert.Equal(t, "John", data.Name)
+
+				return
+			}
+
+			var mediaTypeErr *UnsupportedMediaTypeError
+			require.ErrorAs(t, err, &mediaTypeErr)
+			require.ErrorIs(t, err, ErrInvalidContentType)
+			assert.Equal(t, tc.contentType, mediaTypeErr.ContentType)
+		})
+	}
+}
+
+func TestJSONDecoderBody(t *testing.T) {
+	testCases := []struct {
+		name     string
+		options  []JSONDecoderOption
+		body     func(t *testing.T) io.Reader
+		encoding string
+		check    func(t *testing.T, err error)
+	}{
+		{
+			name: "unknown fields are allowed by default",
+			body: func(*testing.T) io.Reader { return strings.NewReader(`{"name":"John","age":30}`) },
+			check: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:    "unknown field",
+			options: []JSONDecoderOption{WithDisallowUnknownFields()},
+			body:    func(*testing.T) io.Reader { return strings.NewReader(`{"name":"John","age":30}`) },
+			check: func(t *testing.T, err error) {
+				var fieldErr *UnknownJSONFieldError
+				require.ErrorAs(t, err, &fieldErr)
+				assert.Equal(t, "age", fieldErr.Field)
+			},
+		},
+		{
+			name: "empty body",
+			body: func(*testing.T) io.Reader { return strings.NewReader("  ") },
+			check: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrEmptyJSONBody)
+			},
+		},
+		{
+			name: "no body",
+			body: func(*testing.T) io.Reader { return nil },
+			check: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrEmptyJSONBody)
+			},
+		},
+		{
+			name: "syntax error",
+			body: func(*testing.T) io.Reader { return strings.NewReader(`{"name":"John""address":{}}`) },
+			check: func(t *testing.T, err error) {
+				var syntaxErr *JSONSyntaxError
+				require.ErrorAs(t, err, &syntaxErr)
+				assert.Equal(t, int64(15), syntaxErr.Offset)
+
+				var jsonErr *json.SyntaxError
+				require.ErrorAs(t, err, &jsonErr)
+			},
+		},
+		{
+			name: "truncated body",
+			body: func(*testing.T) io.Reader { return strings.NewReader(`{"name":"Jo`) },
+			check: func(t *testing.T, err error) {
+				var syntaxErr *JSONSyntaxError
+				require.ErrorAs(t, err, &syntaxErr)
+				require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+			},
+		},
+		{
+			name: "field type",
+			body: func(*testing.T) io.Reader { return strings.NewReader(`{"address":{"zip":"1234"}}`) },
+			check: func(t *testing.T, err error) {
+				var typeErr *JSONFieldTypeError
+				require.ErrorAs(t, err, &typeErr)
+				assert.Equal(t, JSONFieldTypeError{Field: "address.zip", Value: "string", Type: "int"}, *typeErr)
+			},
+		},
+		{
+			name: "trailing data",
+			body: func(*testing.T) io.Reader { return strings.NewReader(`{"name":"John"} {"name":"Jane"}`) },
+			check: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrTrailingJSONData)
+			},
+		},
+		{
+			name: "trailing garbage",
+			body: func(*testing.T) io.Reader { return strings.NewReader(`{"name":"John"}}`) },
+			check: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrTrailingJSONData)
+			},
+		},
+		{
+			name: "trailing whitespace",
+			body: func(*testing.T) io.Reader { return strings.NewReader("{\"name\":\"John\"}\n") },
+			check: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:    "too large",
+			options: []JSONDecoderOption{WithMaxBytes(10)},
+			body:    func(*testing.T) io.Reader { return strings.NewReader(`{"name":"John"}`) },
+			check: func(t *testing.T, err error) {
+				var maxBytesErr *http.MaxBytesError
+				require.ErrorAs(t, err, &maxBytesErr)
+				assert.Equal(t, int64(10), maxBytesErr.Limit)
+			},
+		},
+		{
+			name:    "unlimited",
+			options: []JSONDecoderOption{WithMaxBytes(0)},
+			body: func(*testing.T) io.Reader {
+				return strings.NewReader(`{"name":"` + strings.Repeat("a", DefaultMaxJSONBodyBytes) + `"}`)
+			},
+			check: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:     "gzip",
+			options:  []JSONDecoderOption{WithGzip()},
+			body:     func(t *testing.T) io.Reader { return gzipBody(t, `{"name":"John"}`) },
+			encoding: "gzip",
+			check: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:     "gzip disabled",
+			body:     func(t *testing.T) io.Reader { return gzipBody(t, `{"name":"John"}`) },
+			encoding: "gzip",
+			check: func(t *testing.T, err error) {
+				var encodingErr *UnsupportedEncodingError
+				require.ErrorAs(t, err, &encodingErr)
+				require.ErrorIs(t, err, ErrInvalidContentType)
+				assert.Equal(t, "gzip", encodingErr.Encoding)
+			},
+		},
+		{
+			name:     "unsupported encoding",
+			options:  []JSONDecoderOption{WithGzip()},
+			body:     func(*testing.T) io.Reader { return strings.NewReader(`{"name":"John"}`) },
+			encoding: "br",
+			check: func(t *testing.T, err error) {
+				var encodingErr *UnsupportedEncodingError
+				require.ErrorAs(t, err, &encodingErr)
+			},
+		},
+		{
+			name:     "invalid gzip",
+			options:  []JSONDecoderOption{WithGzip()},
+			body:     func(*testing.T) io.Reader { return strings.NewReader(`{"name":"John"}`) },
+			encoding: "gzip",
+			check: func(t *testing.T, err error) {
+				var syntaxErr *JSONSyntaxError
+				require.ErrorAs(t, err, &syntaxErr)
+				require.ErrorIs(t, err, gzip.ErrHeader)
+			},
+		},
+		{
+			name:    "decompressed body too large",
+			options: []JSONDecoderOption{WithGzip(), WithMaxBytes(100)},
+			body: func(t *testing.T) io.Reader {
+				return gzipBody(t, `{"name":"`+strings.Repeat("a", 1000)+`"}`)
+			},
+			encoding: "gzip",
+			check: func(t *testing.T, err error) {
+				var maxBytesErr *http.MaxBytesError
+				require.ErrorAs(t, err, &maxBytesErr)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", tc.body(t))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Content-Encoding", tc.encoding)
+
+			var data decoderTestData
+			err := NewJSONDecoder(tc.options...).Decode(req, &data)
+
+			tc.check(t, err)
+
+			if err != nil {
+				var maxBytesErr *http.MaxBytesError
+				if !errors.As(err, &maxBytesErr) {
+					assert.True(t, errors.Is(err, ErrInvalidJSONBody) || errors.Is(err, ErrInvalidContentType), err)
+				}
+			}
+		})
+	}
+}
+
+func TestJSONDecoderProblem(t *testing.T) {
+	testCases := []struct {
+		name           string
+		contentType    string
+		body           string
+		expectedStatus int
+	}{
+		{name: "media type", contentType: "text/plain", body: `{}`, expectedStatus: http.StatusUnsupportedMediaType},
+		{name: "syntax", contentType: "application/json", body: `{`, expectedStatus: http.StatusBadRequest},
+		{name: "trailing data", contentType: "application/json", body: `{}{}`, expectedStatus: http.StatusBadRequest},
+		{
+			name: "too large", contentType: "application/json", body: strings.Repeat(" ", DefaultMaxJSONBodyBytes+1),
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", tc.contentType)
+
+			var data decoderTestData
+
+			rec := httptest.NewRecorder()
+			WriteError(rec, req, ReadJSONRequest(req, &data))
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+		})
+	}
+}
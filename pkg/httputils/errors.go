@@ -0,0 +1,167 @@
+package httputils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+
+	"github.com/adroit-group/gote/pkg/logger"
+	"github.com/go-playground/validator/v10"
+)
+
+// DefaultErrorRegistry is the registry used by WriteError and RegisterError.
+var DefaultErrorRegistry = NewErrorRegistry()
+
+// FieldError describes a field of the request failing the validation, see WriteError.
+type FieldError struct {
+	// Field is the path of the field, e.g. "address.city".
+	Field string `json:"field"`
+	// JSONPath is the JSONPath of the field in the request body, e.g. "$.address.city",
+	// empty for the path, query and header parameters, see BindRequest.
+	JSONPath string `json:"json_path,omitempty"`
+	// Rule is the validation rule that failed, e.g. "required".
+	Rule string `json:"rule"`
+	// Param is the parameter of the rule, e.g. "3" for "min=3".
+	Param string `json:"param,omitempty"`
+	// Message is the human-readable description of the error, e.g. "city must be at least 3 characters in length".
+	Message string `json:"message"`
+}
+
+// ErrorRegistry maps the errors returned by the handlers to the problems written to the clients.
+type ErrorRegistry struct {
+	mu       sync.RWMutex
+	mappings []errorMapping
+}
+
+type errorMapping struct {
+	target  error
+	problem Problem
+}
+
+// NewErrorRegistry creates a registry without any domain errors, see Register.
+func NewErrorRegistry() *ErrorRegistry {
+	return &ErrorRegistry{}
+}
+
+// Register maps the errors matching the target, see errors.Is, to the problem, e.g.
+//
+//	registry.Register(ErrUserNotFound, httputils.Problem{Type: "https://example.com/problems/user-not-found", Status: http.StatusNotFound})
+//
+// The message of the error is used as the detail, unless the problem has its own.
+// Registering the same target again replaces its problem, the targets of non-comparable types, e.g. slices,
+// are added again instead. The errors are matched in the order they are registered.
+func (r *ErrorRegistry) Register(target error, problem Problem) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, mapping := range r.mappings {
+		if sameTarget(mapping.target, target) {
+			r.mappings[i].problem = problem
+
+			return
+		}
+	}
+
+	r.mappings = append(r.mappings, errorMapping{target: target, problem: problem})
+}
+
+// sameTarget reports whether the targets are equal, comparing them only if their type is comparable,
+// like errors.Is does, so the non-comparable errors do not panic.
+func sameTarget(a, b error) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	typ := reflect.TypeOf(a)
+
+	return typ == reflect.TypeOf(b) && typ.Comparable() && a == b
+}
+
+// Problem returns the problem describing the error:
+//   - a *Problem in the chain of the error is returned as it is,
+//   - the registered errors are mapped to their problem,
+//   - *ValidationError and validator.ValidationErrors are mapped to 422 Unprocessable Entity,
+//     with the invalid fields in the "errors" member, see NewValidationProblem,
+//   - ErrInvalidContentType to 415 Unsupported Media Type, ErrInvalidJSONBody and ErrInvalidParam to 400 Bad Request,
+//     *http.MaxBytesError to 413 Content Too Large and context.DeadlineExceeded to 503 Service Unavailable,
+//   - any other error to 500 Internal Server Error, without the message of the error, so the internals are not leaked.
+func (r *ErrorRegistry) Problem(err error) *Problem {
+	var problem *Problem
+	if errors.As(err, &problem) {
+		return problem
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, mapping := range r.mappings {
+		if errors.Is(err, mapping.target) {
+			p := mapping.problem
+			if p.Detail == "" {
+				p.Detail = err.Error()
+			}
+
+			return &p
+		}
+	}
+
+	return builtinProblem(err)
+}
+
+func builtinProblem(err error) *Problem {
+	var (
+		validationErr  *ValidationError
+		validationErrs validator.ValidationErrors
+		maxBytesErr    *http.MaxBytesError
+	)
+
+	switch {
+	case errors.As(err, &validationErr):
+		return NewValidationProblem(validationErr.Fields...)
+	case errors.As(err, &validationErrs):
+		return NewValidationProblem(fieldErrors(validationErrs)...)
+	case errors.Is(err, ErrInvalidContentType):
+		return NewProblem(http.StatusUnsupportedMediaType, err.Error())
+	case errors.Is(err, ErrInvalidJSONBody), errors.Is(err, ErrInvalidParam):
+		return NewProblem(http.StatusBadRequest, err.Error())
+	case errors.As(err, &maxBytesErr):
+		return NewProblem(http.StatusRequestEntityTooLarge, fmt.Sprintf("the request body is larger than %d bytes", maxBytesErr.Limit))
+	case errors.Is(err, context.DeadlineExceeded):
+		return NewProblem(http.StatusServiceUnavailable, "the request timed out")
+	default:
+		return NewProblem(http.StatusInternalServerError, "")
+	}
+}
+
+// WriteError writes the problem of the error, see Problem, with the path and the ID of the request.
+// The server errors are logged with the request logger, as their details are not sent to the client.
+func (r *ErrorRegistry) WriteError(w http.ResponseWriter, req *http.Request, err error) {
+	problem := *r.Problem(err)
+
+	if problem.Status >= http.StatusInternalServerError {
+		logger.FromContext(req.Context()).ErrorContext(req.Context(), "request failed", "error", err, "status", problem.Status)
+	}
+
+	if problem.Instance == "" {
+		problem.Instance = req.URL.Path
+	}
+
+	if problem.RequestID == "" {
+		problem.RequestID = RequestIDFromContext(req.Context())
+	}
+
+	WriteProblem(w, &problem)
+}
+
+// RegisterError registers a domain error in the DefaultErrorRegistry, see ErrorRegistry.Register.
+func RegisterError(target error, problem Problem) {
+	DefaultErrorRegistry.Register(target, problem)
+}
+
+// WriteError writes the problem of the error using the DefaultErrorRegistry, see ErrorRegistry.WriteError.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	DefaultErrorRegistry.WriteError(w, r, err)
+}
@@ -0,0 +1,187 @@
+package httputils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/adroit-group/gote/pkg/logger"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errUserNotFound = errors.New("user not found")
+
+func TestWriteError(t *testing.T) {
+	logger.SetupSlog("test", io.Discard)
+
+	registry := NewErrorRegistry()
+	registry.Register(errUserNotFound, Problem{Type: "https://example.com/problems/not-found", Status: http.StatusBadRequest})
+	// The registration is replaced
+	registry.Register(errUserNotFound, Problem{Type: "https://example.com/problems/user-not-found", Status: http.StatusNotFound})
+
+	type user struct {
+		Name    string `validate:"required"`
+		Address struct {
+			City string `validate:"min=3"`
+		}
+	}
+
+	validationErr := validator.New().Struct(user{Address: struct {
+		City string `validate:"min=3"`
+	}{City: "AB"}})
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(strings.Repeat("a", 10)))
+	_, maxBytesErr := io.ReadAll(http.MaxBytesReader(httptest.NewRecorder(), req.Body, 5))
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected Problem
+	}{
+		{
+			name: "problem",
+			err:  fmt.Errorf("wrapped: %w", NewProblem(http.StatusConflict, "the email is taken")),
+			expected: Problem{
+				Type: DefaultProblemType, Title: "Conflict", Status: http.StatusConflict, Detail: "the email is taken",
+			},
+		},
+		{
+			name: "registered error",
+			err:  fmt.Errorf("user 42: %w", errUserNotFound),
+			expected: Problem{
+				Type: "https://example.com/problems/user-not-found", Title: "Not Found", Status: http.StatusNotFound,
+				Detail: "user 42: user not found",
+			},
+		},
+		{
+			name: "validation errors",
+			err:  validationErr,
+			expected: Problem{
+				Type: DefaultProblemType, Title: "Unprocessable Entity", Status: http.StatusUnprocessableEntity,
+				Detail: "the request is invalid",
+				Extensions: map[string]any{"errors": []any{
+					map[string]any{
+						"field": "Name", "json_path": "$.Name", "rule": "required", "message": "Name failed on the required rule",
+					},
+					map[string]any{
+						"field": "Address.City", "json_path": "$.Address.City", "rule": "min", "param": "3",
+						"message": "Address.City failed on the min rule",
+					},
+				}},
+			},
+		},
+		{
+			name: "invalid content type",
+			err:  ErrInvalidContentType,
+			expected: Problem{
+				Type: DefaultProblemType, Title: "Unsupported Media Type", Status: http.StatusUnsupportedMediaType,
+				Detail: "invalid content type",
+			},
+		},
+		{
+			name: "invalid JSON body",
+			err:  ErrInvalidJSONBody,
+			expected: Problem{
+				Type: DefaultProblemType, Title: "Bad Request", Status: http.StatusBadRequest, Detail: "invalid JSON body",
+			},
+		},
+		{
+			name: "invalid parameter",
+			err:  &ParamError{In: TagQuery, Name: "page", Value: "first", Type: "int"},
+			expected: Problem{
+				Type: DefaultProblemType, Title: "Bad Request", Status: http.StatusBadRequest,
+				Detail: `invalid parameter: query parameter "page" must be int, got "first"`,
+			},
+		},
+		{
+			name: "body too large",
+			err:  maxBytesErr,
+			expected: Problem{
+				Type: DefaultProblemType, Title: "Request Entity Too Large", Status: http.StatusRequestEntityTooLarge,
+				Detail: "the request body is larger than 5 bytes",
+			},
+		},
+		{
+			name: "timeout",
+			err:  fmt.Errorf("query: %w", context.DeadlineExceeded),
+			expected: Problem{
+				Type: DefaultProblemType, Title: "Service Unavailable", Status: http.StatusServiceUnavailable,
+				Detail: "the request timed out",
+			},
+		},
+		{
+			name: "unknown error",
+			err:  errors.New("connection refused"),
+			expected: Problem{
+				Type: DefaultProblemType, Title: "Internal Server Error", Status: http.StatusInternalServerError,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+			req = req.WithContext(ContextWithRequestID(req.Context(), "request-1"))
+
+			rec := httptest.NewRecorder()
+			registry.WriteError(rec, req, tc.err)
+
+			assert.Equal(t, tc.expected.Status, rec.Code)
+			assert.Equal(t, ProblemContentType, rec.Header().Get("Content-Type"))
+
+			var problem Problem
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+
+			tc.expected.Instance = "/users/42"
+			tc.expected.RequestID = "request-1"
+			assert.Equal(t, tc.expected, problem)
+		})
+	}
+}
+
+// errorCodes is a non-comparable error, matched by its Is method.
+type errorCodes []string
+
+func (e errorCodes) Error() string {
+	return strings.Join(e, ", ")
+}
+
+func (e errorCodes) Is(target error) bool {
+	codes, ok := target.(errorCodes)
+
+	return ok && slices.Equal(e, codes)
+}
+
+func TestErrorRegistryNonComparableTarget(t *testing.T) {
+	registry := NewErrorRegistry()
+	registry.Register(errorCodes{"quota_exceeded"}, Problem{Status: http.StatusTooManyRequests})
+
+	require.NotPanics(t, func() {
+		registry.Register(errorCodes{"quota_exceeded"}, Problem{Status: http.StatusPaymentRequired})
+		registry.Register(errUserNotFound, Problem{Status: http.StatusNotFound})
+	})
+
+	assert.Equal(t, http.StatusTooManyRequests, registry.Problem(fmt.Errorf("wrapped: %w", errorCodes{"quota_exceeded"})).Status)
+	assert.Equal(t, http.StatusNotFound, registry.Problem(errUserNotFound).Status)
+}
+
+func TestDefaultErrorRegistry(t *testing.T) {
+	errOutOfCredit := errors.New("out of credit")
+	RegisterError(errOutOfCredit, Problem{Status: http.StatusForbidden, Detail: "your balance is too low"})
+
+	rec := httptest.NewRecorder()
+	WriteError(rec, httptest.NewRequest(http.MethodGet, "/", nil), errOutOfCredit)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.JSONEq(t, `{"type":"about:blank","title":"Forbidden","status":403,"detail":"your balance is too low","instance":"/"}`,
+		rec.Body.String())
+}
@@ -0,0 +1,78 @@
+package httputils
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// HandlerFunc is the business logic of a typed handler, it receives the bound and validated request, see Handle.
+type HandlerFunc[Req, Resp any] func(ctx context.Context, req Req) (Resp, error)
+
+// handlerConfig configures the handlers created by Handle.
+type handlerConfig struct {
+	status   int
+	registry *ErrorRegistry
+}
+
+// HandleOption configures a handler created by Handle.
+type HandleOption func(*handlerConfig)
+
+// WithStatus sets the status code of the successful responses, 200 OK by default.
+// The responses with 204 No Content have no body.
+func WithStatus(status int) HandleOption {
+	return func(c *handlerConfig) {
+		c.status = status
+	}
+}
+
+// WithErrorRegistry sets the registry mapping the errors of the handler to the problems,
+// DefaultErrorRegistry by default.
+func WithErrorRegistry(registry *ErrorRegistry) HandleOption {
+	return func(c *handlerConfig) {
+		c.registry = registry
+	}
+}
+
+// Handle adapts the typed handler to an http.HandlerFunc, e.g.
+//
+//	r.Post("/users", httputils.Handle(validate, users.Create, httputils.WithStatus(http.StatusCreated)))
+//
+// The request is bound to a new Req and validated with BindRequest, so Req must be a struct.
+// The errors of the binding and the handler are written with ErrorRegistry.WriteError,
+// and the result of the handler is written as JSON with WriteJSONResponse.
+func Handle[Req, Resp any](v *validator.Validate, fn HandlerFunc[Req, Resp], opts ...HandleOption) http.HandlerFunc {
+	c := handlerConfig{
+		status:   http.StatusOK,
+		registry: DefaultErrorRegistry,
+	}
+
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req Req
+		if err := BindRequest(r, v, &req); err != nil {
+			c.registry.WriteError(w, r, err)
+
+			return
+		}
+
+		resp, err := fn(r.Context(), req)
+		if err != nil {
+			c.registry.WriteError(w, r, err)
+
+			return
+		}
+
+		if c.status == http.StatusNoContent {
+			w.WriteHeader(c.status)
+
+			return
+		}
+
+		WriteJSONResponse(w, c.status, resp)
+	}
+}
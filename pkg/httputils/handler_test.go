@@ -0,0 +1,131 @@
+package httputils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/adroit-group/gote/pkg/logger"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type handleTestRequest struct {
+	TeamID int    `path:"team"`
+	Name   string `json:"name" validate:"required"`
+}
+
+type handleTestResponse struct {
+	TeamID int    `json:"team_id"`
+	Name   string `json:"name"`
+}
+
+var errHandleTestTaken = errors.New("the name is taken")
+
+func createMember(_ context.Context, req handleTestRequest) (handleTestResponse, error) {
+	switch req.Name {
+	case "taken":
+		return handleTestResponse{}, errHandleTestTaken
+	case "failure":
+		return handleTestResponse{}, errors.New("connection refused")
+	default:
+		return handleTestResponse{TeamID: req.TeamID, Name: req.Name}, nil
+	}
+}
+
+func TestHandle(t *testing.T) {
+	logger.SetupSlog("test", io.Discard)
+
+	registry := NewErrorRegistry()
+	registry.Register(errHandleTestTaken, Problem{Status: http.StatusConflict})
+
+	validate := NewValidator()
+
+	r := chi.NewRouter()
+	r.Post("/teams/{team}/members", Handle(validate, createMember,
+		WithStatus(http.StatusCreated), WithErrorRegistry(registry)))
+	r.Delete("/teams/{team}/members", Handle(validate, func(context.Context, struct{}) (any, error) {
+		return nil, nil
+	}, WithStatus(http.StatusNoContent)))
+
+	testCases := []struct {
+		name           string
+		method         string
+		body           string
+		expectedStatus int
+		expectedType   string
+		expectedBody   string
+	}{
+		{
+			name:           "created",
+			method:         http.MethodPost,
+			body:           `{"name":"John"}`,
+			expectedStatus: http.StatusCreated,
+			expectedType:   "application/json",
+			expectedBody:   `{"team_id":7,"name":"John"}`,
+		},
+		{
+			name:           "invalid body",
+			method:         http.MethodPost,
+			body:           `{"name":`,
+			expectedStatus: http.StatusBadRequest,
+			expectedType:   ProblemContentType,
+		},
+		{
+			name:           "validation",
+			method:         http.MethodPost,
+			body:           `{}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedType:   ProblemContentType,
+		},
+		{
+			name:           "registered error",
+			method:         http.MethodPost,
+			body:           `{"name":"taken"}`,
+			expectedStatus: http.StatusConflict,
+			expectedType:   ProblemContentType,
+		},
+		{
+			name:           "unknown error",
+			method:         http.MethodPost,
+			body:           `{"name":"failure"}`,
+			expectedStatus: http.StatusInternalServerError,
+			expectedType:   ProblemContentType,
+		},
+		{
+			name:           "no content",
+			method:         http.MethodDelete,
+			expectedStatus: http.StatusNoContent,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "/teams/7/members", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.Equal(t, tc.expectedType, rec.Header().Get("Content-Type"))
+
+			switch {
+			case tc.expectedBody != "":
+				assert.JSONEq(t, tc.expectedBody, rec.Body.String())
+			case tc.expectedType == ProblemContentType:
+				var problem Problem
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+				assert.Equal(t, tc.expectedStatus, problem.Status)
+			default:
+				assert.Empty(t, rec.Body.String())
+			}
+		})
+	}
+}
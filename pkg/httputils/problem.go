@@ -0,0 +1,156 @@
+package httputils
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+)
+
+// ProblemContentType is the media type of the problem details, see RFC 9457.
+const ProblemContentType = "application/problem+json"
+
+// DefaultProblemType is the type of the problems without a more specific type,
+// meaning that the problem has no semantics beyond the HTTP status code.
+const DefaultProblemType = "about:blank"
+
+// problemMembers are the members of the problem details defined by Problem, they cannot be used as extensions.
+var problemMembers = map[string]bool{
+	"type":       true,
+	"title":      true,
+	"status":     true,
+	"detail":     true,
+	"instance":   true,
+	"request_id": true,
+}
+
+// Problem is an RFC 9457 problem details object, the standard error response of the service.
+// It implements error, so the handlers can return it, see WriteError.
+type Problem struct {
+	// Type is a URI reference identifying the problem type, DefaultProblemType if empty.
+	Type string `json:"type,omitempty"`
+	// Title is a short, human-readable summary of the problem type, it should not change between the occurrences.
+	Title string `json:"title,omitempty"`
+	// Status is the HTTP status code of the response.
+	Status int `json:"status,omitempty"`
+	// Detail is a human-readable explanation specific to this occurrence of the problem.
+	Detail string `json:"detail,omitempty"`
+	// Instance is a URI reference identifying this occurrence of the problem, e.g. the path of the request.
+	Instance string `json:"instance,omitempty"`
+	// RequestID is the ID of the request, to correlate the response with the logs.
+	RequestID string `json:"request_id,omitempty"`
+	// Extensions are the additional members of the problem, e.g. the invalid fields.
+	// The members of Problem cannot be overwritten by the extensions.
+	Extensions map[string]any `json:"-"`
+}
+
+// NewProblem creates a problem with the status code, the title of the status, and the detail.
+func NewProblem(status int, detail string) *Problem {
+	return &Problem{
+		Type:   DefaultProblemType,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+func (p *Problem) Error() string {
+	if p.Detail == "" {
+		return p.Title
+	}
+
+	return fmt.Sprintf("%s: %s", p.Title, p.Detail)
+}
+
+// With returns a copy of the problem with the extension member added.
+func (p *Problem) With(key string, value any) *Problem {
+	clone := *p
+	clone.Extensions = maps.Clone(p.Extensions)
+
+	if clone.Extensions == nil {
+		clone.Extensions = map[string]any{}
+	}
+
+	clone.Extensions[key] = value
+
+	return &clone
+}
+
+// MarshalJSON encodes the problem with its extensions as members of the same object.
+func (p Problem) MarshalJSON() ([]byte, error) {
+	type problem Problem
+
+	if len(p.Extensions) == 0 {
+		return json.Marshal(problem(p))
+	}
+
+	members := make(map[string]any, len(p.Extensions)+len(problemMembers))
+	for key, value := range p.Extensions {
+		if !problemMembers[key] {
+			members[key] = value
+		}
+	}
+
+	encoded, err := json.Marshal(problem(p))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(encoded, &members); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(members)
+}
+
+// UnmarshalJSON decodes the problem, the unknown members are decoded into the extensions.
+func (p *Problem) UnmarshalJSON(data []byte) error {
+	type problem Problem
+
+	var decoded problem
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	var members map[string]any
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+
+	for key := range problemMembers {
+		delete(members, key)
+	}
+
+	if len(members) > 0 {
+		decoded.Extensions = members
+	}
+
+	*p = Problem(decoded)
+
+	return nil
+}
+
+// WriteProblem writes the problem as an application/problem+json response with its status code.
+// The empty type is set to DefaultProblemType, and the empty title to the text of the status.
+func WriteProblem(w http.ResponseWriter, problem *Problem) {
+	p := *problem
+	if p.Type == "" {
+		p.Type = DefaultProblemType
+	}
+
+	if p.Status == 0 {
+		p.Status = http.StatusInternalServerError
+	}
+
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(p.Status)
+
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		slog.Error("failed to encode problem", "error", err)
+	}
+}
@@ -0,0 +1,85 @@
+package httputils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProblemJSON(t *testing.T) {
+	testCases := []struct {
+		name     string
+		problem  *Problem
+		expected string
+	}{
+		{
+			name:     "without extensions",
+			problem:  NewProblem(http.StatusNotFound, "user 42 not found"),
+			expected: `{"type":"about:blank","title":"Not Found","status":404,"detail":"user 42 not found"}`,
+		},
+		{
+			name: "with extensions",
+			problem: NewProblem(http.StatusForbidden, "").
+				With("balance", 30).
+				With("status", "ignored"),
+			expected: `{"balance":30,"status":403,"title":"Forbidden","type":"about:blank"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			encoded, err := json.Marshal(tc.problem)
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.expected, string(encoded))
+
+			var decoded Problem
+			require.NoError(t, json.Unmarshal(encoded, &decoded))
+			assert.Equal(t, tc.problem.Status, decoded.Status)
+			assert.Equal(t, tc.problem.Detail, decoded.Detail)
+		})
+	}
+}
+
+func TestProblemUnmarshalExtensions(t *testing.T) {
+	var problem Problem
+	require.NoError(t, json.Unmarshal([]byte(`{"type":"about:blank","status":422,"request_id":"abc","errors":["a"]}`), &problem))
+
+	assert.Equal(t, Problem{
+		Type:       DefaultProblemType,
+		Status:     http.StatusUnprocessableEntity,
+		RequestID:  "abc",
+		Extensions: map[string]any{"errors": []any{"a"}},
+	}, problem)
+}
+
+func TestProblemWith(t *testing.T) {
+	original := NewProblem(http.StatusBadRequest, "")
+	extended := original.With("key", "value")
+
+	assert.Nil(t, original.Extensions)
+	assert.Equal(t, map[string]any{"key": "value"}, extended.Extensions)
+}
+
+func TestProblemError(t *testing.T) {
+	assert.EqualError(t, NewProblem(http.StatusNotFound, "user 42 not found"), "Not Found: user 42 not found")
+	assert.EqualError(t, NewProblem(http.StatusNotFound, ""), "Not Found")
+}
+
+func TestWriteProblem(t *testing.T) {
+	rec := httptest.NewRecorder()
+	WriteProblem(rec, &Problem{Type: "https://example.com/problems/out-of-credit", Status: http.StatusForbidden})
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Equal(t, ProblemContentType, rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"type":"https://example.com/problems/out-of-credit","title":"Forbidden","status":403}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	WriteProblem(rec, &Problem{})
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.JSONEq(t, `{"type":"about:blank","title":"Internal Server Error","status":500}`, rec.Body.String())
+}
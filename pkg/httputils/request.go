@@ -1,7 +1,6 @@
 package httputils
 
 import (
-	"encoding/json"
 	"errors"
 	"net/http"
 
@@ -14,27 +13,21 @@
 // ErrInvalidJSONBody is an error that is returned when the JSON body of a request is invalid.
 var ErrInvalidJSONBody = errors.New("invalid JSON body")
 
-// ReadJSONRequest reads a JSON request from the provided http.Request and decodes it into the provided value.
-// It returns an error if the content type of the request is not "application/json" or if the decoding fails.
+// ReadJSONRequest reads a JSON request from the provided http.Request and decodes it into the provided value
+// with the DefaultJSONDecoder. It returns an error matching ErrInvalidContentType if the media type of the request
+// is not supported, an error matching ErrInvalidJSONBody if the decoding fails, or *http.MaxBytesError if the body
+// is too large, see JSONDecoder.Decode.
 func ReadJSONRequest[T any](r *http.Request, v *T) error {
-	if r.Header.Get("Content-Type") != "application/json" {
-		return ErrInvalidContentType
-	}
-
-	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
-		return errors.Join(err, ErrInvalidJSONBody)
-	}
-
-	return nil
+	return DefaultJSONDecoder.Decode(r, v)
 }
 
 // ValidateAndReadJSONRequest reads a JSON request from the provided http.Request, decodes it into the provided value,
 // and validates the value using the provided validator. It returns an error if the content type of the request is not
-// "application/json", if the decoding fails, or if the validation fails.
+// supported, if the decoding fails, see ReadJSONRequest, or a *ValidationError if the validation fails, see NewValidationError.
 func ValidateAndReadJSONRequest[T any](r *http.Request, v *validator.Validate, t *T) error {
 	if err := ReadJSONRequest(r, t); err != nil {
 		return err
 	}
 
-	return v.Struct(t)
+	return validationError(r, v, v.Struct(t))
 }
@@ -0,0 +1,18 @@
+package httputils
+
+import "context"
+
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a copy of the context with the request ID, e.g. to propagate it to the background jobs.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID of the context, set by the httpmiddleware.RequestID middleware,
+// or an empty string.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+
+	return id
+}
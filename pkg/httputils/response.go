@@ -8,6 +8,8 @@
 
 // ErrorResponse is a struct that represents an error response.
 // It contains an error message and a status code.
+//
+// Deprecated: Use Problem instead, see WriteError.
 type ErrorResponse struct {
 	Error  string `json:"error"`  // Error message
 	Status int    `json:"status"` // HTTP status code
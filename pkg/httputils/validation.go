@@ -0,0 +1,237 @@
+package httputils
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/de"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
+	"github.com/go-playground/locales/fr"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	detranslations "github.com/go-playground/validator/v10/translations/de"
+	entranslations "github.com/go-playground/validator/v10/translations/en"
+	estranslations "github.com/go-playground/validator/v10/translations/es"
+	frtranslations "github.com/go-playground/validator/v10/translations/fr"
+)
+
+// validationLocales are the supported languages of the validation messages, the first one is the fallback.
+var validationLocales = []struct {
+	locale   func() locales.Translator
+	register func(*validator.Validate, ut.Translator) error
+}{
+	{locale: en.New, register: entranslations.RegisterDefaultTranslations},
+	{locale: de.New, register: detranslations.RegisterDefaultTranslations},
+	{locale: es.New, register: estranslations.RegisterDefaultTranslations},
+	{locale: fr.New, register: frtranslations.RegisterDefaultTranslations},
+}
+
+// translators are the translators of the validation messages registered on the validators, see translatorFor.
+var (
+	translatorsMu sync.Mutex
+	translators   = map[*validator.Validate]*ut.UniversalTranslator{}
+)
+
+// ValidationError is returned when the request fails the validation, it is written as
+// a 422 Unprocessable Entity problem with the invalid fields, see NewValidationProblem.
+type ValidationError struct {
+	// Fields are the invalid fields, with the messages in the language of the client.
+	Fields []FieldError
+	errs   validator.ValidationErrors
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Message)
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap returns the validator.ValidationErrors the error was created from.
+func (e *ValidationError) Unwrap() error {
+	return e.errs
+}
+
+// NewValidator creates a validator naming the fields by their JSON names, e.g. "address.city"
+// instead of "Address.City", or by their parameter names, see BindRequest, with the validation messages registered in the supported languages.
+func NewValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(fieldName)
+
+	translatorFor(v)
+
+	return v
+}
+
+// fieldName returns the name of the field in the JSON documents or the name of its request parameter,
+// the name of the Go field if it has neither.
+func fieldName(field reflect.StructField) string {
+	if param, ok := paramOf(field); ok {
+		return param.name
+	}
+
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+
+	return name
+}
+
+// NewValidationError translates the validation errors to the first supported language accepted by the request,
+// see the Accept-Language header, or to English if none is supported. The fields are named as the validator names them, see NewValidator.
+func NewValidationError(r *http.Request, v *validator.Validate, errs validator.ValidationErrors) *ValidationError {
+	translator, _ := translatorFor(v).FindTranslator(acceptedLocales(r.Header.Get("Accept-Language"))...)
+
+	fields := make([]FieldError, 0, len(errs))
+	for _, fieldErr := range errs {
+		field := newFieldError(fieldErr)
+		field.Message = fieldErr.Translate(translator)
+
+		fields = append(fields, field)
+	}
+
+	return &ValidationError{Fields: fields, errs: errs}
+}
+
+// NewValidationProblem creates the 422 Unprocessable Entity problem of the invalid fields,
+// the fields are listed in the "errors" member.
+func NewValidationProblem(fields ...FieldError) *Problem {
+	return NewProblem(http.StatusUnprocessableEntity, "the request is invalid").With("errors", fields)
+}
+
+// translatorFor returns the translator of the validation messages of the validator,
+// the messages are registered on the validator when it is first used.
+func translatorFor(v *validator.Validate) *ut.UniversalTranslator {
+	translatorsMu.Lock()
+	defer translatorsMu.Unlock()
+
+	if translator, ok := translators[v]; ok {
+		return translator
+	}
+
+	supported := make([]locales.Translator, 0, len(validationLocales))
+	for _, l := range validationLocales {
+		supported = append(supported, l.locale())
+	}
+
+	translator := ut.New(supported[0], supported...)
+
+	for i, l := range validationLocales {
+		trans, _ := translator.GetTranslator(supported[i].Locale())
+		if err := l.register(v, trans); err != nil {
+			slog.Error("failed to register the validation messages", "locale", supported[i].Locale(), "error", err)
+		}
+	}
+
+	translators[v] = translator
+
+	return translator
+}
+
+// acceptedLocales returns the locales of the Accept-Language header in the order of preference,
+// a regional locale is followed by its language, e.g. "de-CH" by "de".
+func acceptedLocales(header string) []string {
+	type acceptedLocale struct {
+		locale  string
+		quality float64
+	}
+
+	var accepted []acceptedLocale
+
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		quality := 1.0
+
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+
+			quality = parsed
+		}
+
+		if quality > 0 {
+			accepted = append(accepted, acceptedLocale{locale: tag, quality: quality})
+		}
+	}
+
+	slices.SortStableFunc(accepted, func(a, b acceptedLocale) int {
+		switch {
+		case a.quality > b.quality:
+			return -1
+		case a.quality < b.quality:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	result := make([]string, 0, 2*len(accepted))
+	for _, a := range accepted {
+		locale := strings.ReplaceAll(a.locale, "-", "_")
+		result = append(result, locale)
+
+		if language, _, ok := strings.Cut(locale, "_"); ok {
+			result = append(result, language)
+		}
+	}
+
+	return result
+}
+
+// newFieldError converts the validation error of a field, without the message.
+// The field is named without the name of the validated struct, e.g. "address.city".
+func newFieldError(fieldErr validator.FieldError) FieldError {
+	field := fieldErr.Namespace()
+	if _, rest, ok := strings.Cut(field, "."); ok {
+		field = rest
+	}
+
+	return FieldError{
+		Field:    field,
+		JSONPath: "$." + field,
+		Rule:     fieldErr.Tag(),
+		Param:    fieldErr.Param(),
+	}
+}
+
+// fieldErrors converts the validation errors created without NewValidationError, the messages are not translated.
+func fieldErrors(validationErrs validator.ValidationErrors) []FieldError {
+	fields := make([]FieldError, 0, len(validationErrs))
+
+	for _, fieldErr := range validationErrs {
+		field := newFieldError(fieldErr)
+		field.Message = fmt.Sprintf("%s failed on the %s rule", field.Field, field.Rule)
+
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+// validationError converts the validation errors of the validator to a ValidationError, see NewValidationError.
+func validationError(r *http.Request, v *validator.Validate, err error) error {
+	var errs validator.ValidationErrors
+	if errors.As(err, &errs) {
+		return NewValidationError(r, v, errs)
+	}
+
+	return err
+}
@@ -0,0 +1,135 @@
+package httputils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type validationTestItem struct {
+	SKU string `json:"sku" validate:"required"`
+}
+
+type validationTestRequest struct {
+	Name    string `json:"name" validate:"required"`
+	Email   string `json:"email,omitempty" validate:"omitempty,email"`
+	Age     int    `validate:"gte=18"`
+	Address struct {
+		City string `json:"city" validate:"min=3"`
+	} `json:"address"`
+	Items []validationTestItem `json:"items" validate:"dive"`
+}
+
+func TestValidateAndReadJSONRequestErrors(t *testing.T) {
+	validate := NewValidator()
+
+	testCases := []struct {
+		name           string
+		acceptLanguage string
+		expected       []FieldError
+	}{
+		{
+			name: "default language",
+			expected: []FieldError{
+				{Field: "name", JSONPath: "$.name", Rule: "required", Message: "name is a required field"},
+				{Field: "email", JSONPath: "$.email", Rule: "email", Message: "email must be a valid email address"},
+				{Field: "Age", JSONPath: "$.Age", Rule: "gte", Param: "18", Message: "Age must be 18 or greater"},
+				{
+					Field: "address.city", JSONPath: "$.address.city", Rule: "min", Param: "3",
+					Message: "city must be at least 3 characters in length",
+				},
+				{Field: "items[1].sku", JSONPath: "$.items[1].sku", Rule: "required", Message: "sku is a required field"},
+			},
+		},
+		{
+			name:           "accepted language",
+			acceptLanguage: "hu, de-CH;q=0.9, en;q=0.8",
+			expected: []FieldError{
+				{Field: "name", JSONPath: "$.name", Rule: "required", Message: "name ist ein Pflichtfeld"},
+				{Field: "email", JSONPath: "$.email", Rule: "email", Message: "email muss eine gültige E-Mail-Adresse sein"},
+				{Field: "Age", JSONPath: "$.Age", Rule: "gte", Param: "18", Message: "Age muss 18 oder größer sein"},
+				{
+					Field: "address.city", JSONPath: "$.address.city", Rule: "min", Param: "3",
+					Message: "city muss mindestens 3 Zeichen lang sein",
+				},
+				{Field: "items[1].sku", JSONPath: "$.items[1].sku", Rule: "required", Message: "sku ist ein Pflichtfeld"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			body := `{"email":"invalid","Age":17,"address":{"city":"AB"},"items":[{"sku":"A1"},{}]}`
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Accept-Language", tc.acceptLanguage)
+
+			var data validationTestRequest
+			err := ValidateAndReadJSONRequest(req, validate, &data)
+
+			var validationErr *ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tc.expected, validationErr.Fields)
+
+			var validationErrs validator.ValidationErrors
+			require.ErrorAs(t, err, &validationErrs)
+			assert.Len(t, validationErrs, len(tc.expected))
+		})
+	}
+}
+
+func TestWriteValidationError(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	var data validationTestRequest
+	err := ValidateAndReadJSONRequest(req, NewValidator(), &data)
+	require.Error(t, err)
+
+	rec := httptest.NewRecorder()
+	WriteError(rec, req, err)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	var problem Problem
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+	assert.Equal(t, "the request is invalid", problem.Detail)
+	assert.Equal(t, []any{
+		map[string]any{"field": "name", "json_path": "$.name", "rule": "required", "message": "name is a required field"},
+		map[string]any{"field": "Age", "json_path": "$.Age", "rule": "gte", "param": "18", "message": "Age must be 18 or greater"},
+		map[string]any{
+			"field": "address.city", "json_path": "$.address.city", "rule": "min", "param": "3",
+			"message": "city must be at least 3 characters in length",
+		},
+	}, problem.Extensions["errors"])
+}
+
+func TestAcceptedLocales(t *testing.T) {
+	testCases := []struct {
+		name     string
+		header   string
+		expected []string
+	}{
+		{name: "empty", header: "", expected: []string{}},
+		{name: "single", header: "fr", expected: []string{"fr"}},
+		{name: "regional", header: "pt-BR", expected: []string{"pt_BR", "pt"}},
+		{
+			name:     "quality",
+			header:   "en;q=0.5, de-DE, fr;q=0.8, *;q=0.1, es;q=0",
+			expected: []string{"de_DE", "de", "fr", "en"},
+		},
+		{name: "invalid quality", header: "en;q=x, de", expected: []string{"de"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, acceptedLocales(tc.header))
+		})
+	}
+}
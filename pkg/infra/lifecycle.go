@@ -0,0 +1,381 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// DefaultStartTimeout is the start timeout of the components added without WithStartTimeout.
+	DefaultStartTimeout = 15 * time.Second
+	// DefaultStopTimeout is the stop timeout of the components added without WithStopTimeout.
+	DefaultStopTimeout = 5 * time.Second
+)
+
+var (
+	// ErrDuplicateComponent is returned when a component is added with the name of another one.
+	ErrDuplicateComponent = errors.New("duplicate component")
+	// ErrUnknownDependency is returned when a component depends on a component that is not added.
+	ErrUnknownDependency = errors.New("unknown dependency")
+	// ErrDependencyCycle is returned when the dependencies of the components form a cycle.
+	ErrDependencyCycle = errors.New("dependency cycle")
+)
+
+// Phase is a phase of the lifecycle of a component.
+type Phase string
+
+const (
+	// PhaseStart is the phase when the component is started.
+	PhaseStart Phase = "start"
+	// PhaseRun is the phase when the component is running in the background.
+	PhaseRun Phase = "run"
+	// PhaseStop is the phase when the component is stopped.
+	PhaseStop Phase = "stop"
+	// PhaseBeforeShutdown is the phase of the graceful shutdown when the hooks before the shutdown are called.
+	PhaseBeforeShutdown Phase = "before_shutdown"
+	// PhaseAfterShutdown is the phase of the graceful shutdown when the hooks after the shutdown are called.
+	PhaseAfterShutdown Phase = "after_shutdown"
+)
+
+// Component is a part of the service with a lifecycle, e.g. a server, a consumer or a connection pool.
+type Component interface {
+	// Start starts the component, and returns once it is started, the work can continue in the background.
+	// The context is canceled when the start timeout of the component expires.
+	Start(ctx context.Context) error
+	// Stop stops the component and releases its resources.
+	// The context is canceled when the stop timeout of the component expires.
+	Stop(ctx context.Context) error
+}
+
+// Waiter is implemented by the components running in the background, which can fail after they started.
+type Waiter interface {
+	// Wait blocks until the component stops, and returns the error that stopped it.
+	// It returns nil if the component was stopped by Stop.
+	Wait() error
+}
+
+// ComponentFuncs is an adapter to allow the use of ordinary functions as components.
+// Both functions are optional.
+type ComponentFuncs struct {
+	StartFunc func(ctx context.Context) error
+	StopFunc  func(ctx context.Context) error
+}
+
+// Start calls StartFunc, if it is set.
+func (c ComponentFuncs) Start(ctx context.Context) error {
+	if c.StartFunc == nil {
+		return nil
+	}
+
+	return c.StartFunc(ctx)
+}
+
+// Stop calls StopFunc, if it is set.
+func (c ComponentFuncs) Stop(ctx context.Context) error {
+	if c.StopFunc == nil {
+		return nil
+	}
+
+	return c.StopFunc(ctx)
+}
+
+// ComponentError is returned when a component fails in one of the phases of its lifecycle.
+type ComponentError struct {
+	Name  string
+	Phase Phase
+	Err   error
+}
+
+func (e *ComponentError) Error() string {
+	return fmt.Sprintf("component %q failed to %s: %v", e.Name, e.Phase, e.Err)
+}
+
+func (e *ComponentError) Unwrap() error {
+	return e.Err
+}
+
+// ComponentOption configures a component added to a Lifecycle.
+type ComponentOption func(*component)
+
+// DependsOn makes the component start after, and stop before the components with the names.
+func DependsOn(names ...string) ComponentOption {
+	return func(c *component) {
+		c.dependencies = append(c.dependencies, names...)
+	}
+}
+
+// WithStartTimeout sets the start timeout of the component, DefaultStartTimeout by default.
+func WithStartTimeout(timeout time.Duration) ComponentOption {
+	return func(c *component) {
+		c.startTimeout = timeout
+	}
+}
+
+// WithStopTimeout sets the stop timeout of the component, DefaultStopTimeout by default.
+func WithStopTimeout(timeout time.Duration) ComponentOption {
+	return func(c *component) {
+		c.stopTimeout = timeout
+	}
+}
+
+type component struct {
+	name         string
+	component    Component
+	dependencies []string
+	startTimeout time.Duration
+	stopTimeout  time.Duration
+}
+
+// Lifecycle starts the components in the order of their dependencies, and stops them in the reverse order.
+// If a component fails, the rest of them are stopped as well.
+type Lifecycle struct {
+	mu         sync.Mutex
+	components []*component
+	started    []*component
+	running    *errgroup.Group
+	failed     <-chan struct{}
+}
+
+// NewLifecycle creates an empty Lifecycle.
+func NewLifecycle() *Lifecycle {
+	return &Lifecycle{}
+}
+
+// Add adds a named component. The components without dependencies are started in the order they are added.
+func (l *Lifecycle) Add(name string, c Component, opts ...ComponentOption) error {
+	added := &component{
+		name:         name,
+		component:    c,
+		startTimeout: DefaultStartTimeout,
+		stopTimeout:  DefaultStopTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(added)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, existing := range l.components {
+		if existing.name == name {
+			return fmt.Errorf("%w: %q", ErrDuplicateComponent, name)
+		}
+	}
+
+	l.components = append(l.components, added)
+
+	return nil
+}
+
+// Run starts the components, and stops them when the context is canceled, or any of them fails.
+// It returns once every started component is stopped, with the errors of the failed phases, see ComponentError.
+func (l *Lifecycle) Run(ctx context.Context) error {
+	startErr := l.Start(ctx)
+	if startErr == nil {
+		select {
+		case <-ctx.Done():
+		case <-l.Failed():
+		}
+	}
+
+	return errors.Join(startErr, l.Stop())
+}
+
+// Start starts the components in the order of their dependencies, until one of them fails,
+// or the context is canceled. The started components keep running until Stop is called, even if the start fails.
+// Use Run, unless something has to happen between the start and the stop, e.g. a graceful shutdown.
+func (l *Lifecycle) Start(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ordered, err := startOrder(l.components)
+	if err != nil {
+		return err
+	}
+
+	// The background components run until they are stopped, not until the context of the start is canceled
+	running, failedCtx := errgroup.WithContext(context.WithoutCancel(ctx))
+	l.running = running
+	l.failed = failedCtx.Done()
+
+	// A component failing in the background interrupts the start
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer context.AfterFunc(failedCtx, cancel)()
+
+	l.started, err = start(ctx, running, ordered)
+
+	return err
+}
+
+// Failed returns a channel closed when a started component fails in the background, see Waiter.
+func (l *Lifecycle) Failed() <-chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.failed
+}
+
+// Stop stops the started components in the reverse order, and waits for the background components to return.
+// It returns the errors of the failed phases, see ComponentError.
+func (l *Lifecycle) Stop() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.running == nil {
+		return nil
+	}
+
+	stopErr := stop(l.started)
+
+	return errors.Join(l.running.Wait(), stopErr)
+}
+
+// start starts the components in order, until one of them fails or the context is canceled,
+// and waits for the background components in the errgroup. It returns the started components.
+func start(ctx context.Context, eg *errgroup.Group, ordered []*component) ([]*component, error) {
+	started := make([]*component, 0, len(ordered))
+
+	for _, c := range ordered {
+		if ctx.Err() != nil {
+			return started, nil
+		}
+
+		slog.Info("starting component", "component", c.name)
+
+		if err := callWithTimeout(ctx, c.startTimeout, c.component.Start); err != nil {
+			if errors.Is(err, context.Canceled) && ctx.Err() != nil {
+				// The start was interrupted by the shutdown, the component is stopped like the started ones
+				return append(started, c), nil
+			}
+
+			return started, &ComponentError{Name: c.name, Phase: PhaseStart, Err: err}
+		}
+
+		started = append(started, c)
+
+		if waiter, ok := c.component.(Waiter); ok {
+			eg.Go(func() error {
+				if err := waiter.Wait(); err != nil {
+					return &ComponentError{Name: c.name, Phase: PhaseRun, Err: err}
+				}
+
+				return nil
+			})
+		}
+	}
+
+	return started, nil
+}
+
+// stop stops the components in the reverse order, each with its own timeout, even if the previous ones failed.
+func stop(started []*component) error {
+	var errs []error
+
+	for i := len(started) - 1; i >= 0; i-- {
+		c := started[i]
+
+		slog.Info("stopping component", "component", c.name)
+
+		if err := callWithTimeout(context.Background(), c.stopTimeout, c.component.Stop); err != nil {
+			errs = append(errs, &ComponentError{Name: c.name, Phase: PhaseStop, Err: err})
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// callWithTimeout calls fn with a context canceled after the timeout,
+// and returns when the timeout expires, even if fn ignores the context.
+func callWithTimeout(ctx context.Context, timeout time.Duration, fn func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := callWithContext(ctx, fn)
+	if errors.Is(err, context.DeadlineExceeded) && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s: %w", timeout, err)
+	}
+
+	return err
+}
+
+// callWithContext calls fn with the context, and returns when the context is canceled, even if fn ignores it.
+func callWithContext(ctx context.Context, fn func(ctx context.Context) error) error {
+	done := make(chan error, 1)
+
+	go func() {
+		done <- fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// startOrder sorts the components topologically by their dependencies,
+// keeping the order they were added in where the dependencies allow it.
+func startOrder(components []*component) ([]*component, error) {
+	byName := make(map[string]*component, len(components))
+	for _, c := range components {
+		byName[c.name] = c
+	}
+
+	for _, c := range components {
+		for _, dependency := range c.dependencies {
+			if _, ok := byName[dependency]; !ok {
+				return nil, fmt.Errorf("%w: %q depends on %q", ErrUnknownDependency, c.name, dependency)
+			}
+		}
+	}
+
+	ordered := make([]*component, 0, len(components))
+	done := make(map[string]bool, len(components))
+
+	for len(ordered) < len(components) {
+		progressed := false
+
+		for _, c := range components {
+			if done[c.name] || !dependenciesDone(c, done) {
+				continue
+			}
+
+			ordered = append(ordered, c)
+			done[c.name] = true
+			progressed = true
+		}
+
+		if !progressed {
+			var remaining []string
+
+			for _, c := range components {
+				if !done[c.name] {
+					remaining = append(remaining, c.name)
+				}
+			}
+
+			return nil, fmt.Errorf("%w between %q", ErrDependencyCycle, remaining)
+		}
+	}
+
+	return ordered, nil
+}
+
+func dependenciesDone(c *component, done map[string]bool) bool {
+	for _, dependency := range c.dependencies {
+		if !done[dependency] {
+			return false
+		}
+	}
+
+	return true
+}
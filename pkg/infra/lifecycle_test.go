@@ -0,0 +1,324 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/adroit-group/gote/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errBroken = errors.New("broker unavailable")
+
+// recorder records the lifecycle events of the components.
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recorder) record(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, event)
+}
+
+func (r *recorder) component(name string) ComponentFuncs {
+	return ComponentFuncs{
+		StartFunc: func(context.Context) error {
+			r.record("start " + name)
+
+			return nil
+		},
+		StopFunc: func(context.Context) error {
+			r.record("stop " + name)
+
+			return nil
+		},
+	}
+}
+
+// failingComponent fails in the background after it started.
+type failingComponent struct {
+	ComponentFuncs
+
+	failed chan error
+}
+
+func (c failingComponent) Wait() error {
+	return <-c.failed
+}
+
+func TestLifecycleOrder(t *testing.T) {
+	logger.SetupSlog("test", io.Discard)
+
+	r := &recorder{}
+	l := NewLifecycle()
+
+	require.NoError(t, l.Add("http", r.component("http"), DependsOn("database", "consumer")))
+	require.NoError(t, l.Add("consumer", r.component("consumer"), DependsOn("database")))
+	require.NoError(t, l.Add("database", r.component("database")))
+	require.NoError(t, l.Add("metrics", r.component("metrics")))
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	require.NoError(t, l.Add("shutdown", ComponentFuncs{
+		StartFunc: func(context.Context) error {
+			cancel()
+
+			return nil
+		},
+	}, DependsOn("http")))
+
+	require.NoError(t, l.Run(ctx))
+	assert.Equal(t, []string{
+		"start database",
+		"start metrics",
+		"start consumer",
+		"start http",
+		"stop http",
+		"stop consumer",
+		"stop metrics",
+		"stop database",
+	}, r.events)
+}
+
+func TestLifecycleInvalidDependencies(t *testing.T) {
+	testCases := []struct {
+		name        string
+		add         func(l *Lifecycle) error
+		expectedErr error
+	}{
+		{
+			name: "duplicate component",
+			add: func(l *Lifecycle) error {
+				require.NoError(t, l.Add("database", ComponentFuncs{}))
+
+				return l.Add("database", ComponentFuncs{})
+			},
+			expectedErr: ErrDuplicateComponent,
+		},
+		{
+			name: "unknown dependency",
+			add: func(l *Lifecycle) error {
+				require.NoError(t, l.Add("http", ComponentFuncs{}, DependsOn("database")))
+
+				return l.Run(context.Background())
+			},
+			expectedErr: ErrUnknownDependency,
+		},
+		{
+			name: "dependency cycle",
+			add: func(l *Lifecycle) error {
+				require.NoError(t, l.Add("a", ComponentFuncs{}, DependsOn("b")))
+				require.NoError(t, l.Add("b", ComponentFuncs{}, DependsOn("a")))
+
+				return l.Run(context.Background())
+			},
+			expectedErr: ErrDependencyCycle,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.ErrorIs(t, tc.add(NewLifecycle()), tc.expectedErr)
+		})
+	}
+}
+
+func TestLifecycleStartFailure(t *testing.T) {
+	logger.SetupSlog("test", io.Discard)
+
+	r := &recorder{}
+	l := NewLifecycle()
+
+	require.NoError(t, l.Add("database", r.component("database")))
+	require.NoError(t, l.Add("consumer", ComponentFuncs{
+		StartFunc: func(context.Context) error { return errBroken },
+	}))
+	require.NoError(t, l.Add("http", r.component("http")))
+
+	err := l.Run(context.Background())
+	require.ErrorIs(t, err, errBroken)
+
+	var componentErr *ComponentError
+	require.ErrorAs(t, err, &componentErr)
+	assert.Equal(t, "consumer", componentErr.Name)
+	assert.Equal(t, PhaseStart, componentErr.Phase)
+	assert.Equal(t, []string{"start database", "stop database"}, r.events)
+}
+
+func TestLifecycleRunFailure(t *testing.T) {
+	logger.SetupSlog("test", io.Discard)
+
+	r := &recorder{}
+	failed := make(chan error, 1)
+
+	l := NewLifecycle()
+	require.NoError(t, l.Add("database", r.component("database")))
+	require.NoError(t, l.Add("consumer", failingComponent{ComponentFuncs: r.component("consumer"), failed: failed}))
+
+	failed <- errBroken
+
+	err := l.Run(context.Background())
+	require.ErrorIs(t, err, errBroken)
+
+	var componentErr *ComponentError
+	require.ErrorAs(t, err, &componentErr)
+	assert.Equal(t, PhaseRun, componentErr.Phase)
+	assert.Equal(t, []string{"start database", "start consumer", "stop consumer", "stop database"}, r.events)
+}
+
+func TestLifecycleTimeouts(t *testing.T) {
+	logger.SetupSlog("test", io.Discard)
+
+	block := func(context.Context) error {
+		time.Sleep(time.Second)
+
+		return nil
+	}
+
+	testCases := []struct {
+		name          string
+		component     Component
+		opts          []ComponentOption
+		expectedPhase Phase
+	}{
+		{
+			name:          "start timeout",
+			component:     ComponentFuncs{StartFunc: block},
+			opts:          []ComponentOption{WithStartTimeout(10 * time.Millisecond)},
+			expectedPhase: PhaseStart,
+		},
+		{
+			name:          "stop timeout",
+			component:     ComponentFuncs{StopFunc: block},
+			opts:          []ComponentOption{WithStopTimeout(10 * time.Millisecond)},
+			expectedPhase: PhaseStop,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			l := NewLifecycle()
+			require.NoError(t, l.Add("slow", tc.component, tc.opts...))
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			err := l.Run(ctx)
+			require.ErrorIs(t, err, context.DeadlineExceeded)
+
+			var componentErr *ComponentError
+			require.ErrorAs(t, err, &componentErr)
+			assert.Equal(t, tc.expectedPhase, componentErr.Phase)
+		})
+	}
+}
+
+func TestLifecycleHTTPServerComponent(t *testing.T) {
+	logger.SetupSlog("test", io.Discard)
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	srv := &http.Server{
+		Addr: addr,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+	}
+
+	l := NewLifecycle()
+	require.NoError(t, l.Add("http", NewHTTPServerComponent(srv)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- l.Run(ctx)
+	}()
+
+	client := &http.Client{Timeout: time.Second}
+
+	require.Eventually(t, func() bool {
+		resp, err := client.Get("http://" + addr)
+		if err != nil {
+			return false
+		}
+
+		return resp.Body.Close() == nil && resp.StatusCode == http.StatusOK
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+
+	select {
+	case err := <-errCh:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "lifecycle did not stop within expected time")
+	}
+}
+
+func TestLifecycleHTTPServerComponentListenFailure(t *testing.T) {
+	logger.SetupSlog("test", io.Discard)
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	defer func() {
+		require.NoError(t, listener.Close())
+	}()
+
+	l := NewLifecycle()
+	require.NoError(t, l.Add("http", NewHTTPServerComponent(&http.Server{Addr: listener.Addr().String()})))
+
+	var componentErr *ComponentError
+	require.ErrorAs(t, l.Run(context.Background()), &componentErr)
+	assert.Equal(t, PhaseStart, componentErr.Phase)
+}
+
+func TestLifecycleStartStop(t *testing.T) {
+	logger.SetupSlog("test", io.Discard)
+
+	r := &recorder{}
+	failed := make(chan error, 1)
+
+	l := NewLifecycle()
+	require.NoError(t, l.Add("database", r.component("database")))
+	require.NoError(t, l.Add("consumer", failingComponent{ComponentFuncs: r.component("consumer"), failed: failed}))
+
+	require.NoError(t, l.Start(context.Background()))
+	assert.Equal(t, []string{"start database", "start consumer"}, r.events)
+
+	select {
+	case <-l.Failed():
+		require.Fail(t, "the lifecycle failed before a component failed")
+	default:
+	}
+
+	failed <- errBroken
+
+	select {
+	case <-l.Failed():
+	case <-time.After(time.Second):
+		require.Fail(t, "the failure was not reported")
+	}
+
+	// The components are only stopped by Stop
+	assert.Equal(t, []string{"start database", "start consumer"}, r.events)
+
+	err := l.Stop()
+	require.ErrorIs(t, err, errBroken)
+	assert.Equal(t, []string{"start database", "start consumer", "stop consumer", "stop database"}, r.events)
+}
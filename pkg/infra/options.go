@@ -0,0 +1,119 @@
+package infra
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"syscall"
+	"time"
+)
+
+// DefaultShutdownTimeout is the timeout of the graceful shutdown without WithShutdownTimeout.
+const DefaultShutdownTimeout = 5 * time.Second
+
+// DefaultSignals are the signals triggering the graceful shutdown without WithSignals.
+// SIGTERM is sent by Kubernetes and Docker to stop the containers, os.Interrupt by Ctrl+C.
+var DefaultSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// Drainer is notified when the graceful shutdown starts, before the server stops accepting connections,
+// e.g. to fail the readiness probe, so the load balancers stop routing new requests to the instance.
+type Drainer interface {
+	Drain()
+}
+
+// ShutdownHook is called during the graceful shutdown.
+// The context is canceled when the shutdown timeout expires, or the shutdown is forced.
+type ShutdownHook func(ctx context.Context) error
+
+// Option configures RunHTTPServerWithGracefulShutdown.
+type Option func(*options)
+
+type options struct {
+	drainPeriod     time.Duration
+	drainers        []Drainer
+	shutdownTimeout time.Duration
+	signals         []os.Signal
+	beforeShutdown  []ShutdownHook
+	afterShutdown   []ShutdownHook
+	adminServers    []*http.Server
+	components      []namedComponent
+}
+
+type namedComponent struct {
+	name      string
+	component Component
+	opts      []ComponentOption
+}
+
+// WithDrain notifies the drainers when the shutdown starts,
+// and keeps serving the requests for the drain period before the server stops accepting connections.
+// The period should be longer than the interval of the readiness probes of the load balancers.
+func WithDrain(period time.Duration, drainers ...Drainer) Option {
+	return func(o *options) {
+		o.drainPeriod = period
+		o.drainers = append(o.drainers, drainers...)
+	}
+}
+
+// WithShutdownTimeout sets the timeouts of the graceful shutdown after the drain period, DefaultShutdownTimeout
+// by default. The hooks before the shutdown, each server, and the hooks after the shutdown are limited by it.
+func WithShutdownTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.shutdownTimeout = timeout
+	}
+}
+
+// WithSignals sets the signals triggering the graceful shutdown, DefaultSignals by default.
+// Receiving any of them again forces the shutdown.
+func WithSignals(signals ...os.Signal) Option {
+	return func(o *options) {
+		o.signals = signals
+	}
+}
+
+// WithBeforeShutdown adds hooks called in order after the drain period, before the server is shut down,
+// e.g. to stop the background jobs accepting new work.
+func WithBeforeShutdown(hooks ...ShutdownHook) Option {
+	return func(o *options) {
+		o.beforeShutdown = append(o.beforeShutdown, hooks...)
+	}
+}
+
+// WithAfterShutdown adds hooks called in order after the server is shut down,
+// e.g. to flush the telemetry or close the database connections.
+func WithAfterShutdown(hooks ...ShutdownHook) Option {
+	return func(o *options) {
+		o.afterShutdown = append(o.afterShutdown, hooks...)
+	}
+}
+
+// WithAdminServer runs the admin server, serving the operational endpoints on a separate port, with the main server.
+// It is started before, and shut down after the main server, so the probes are served during the drain period.
+// If it fails, the main server is drained and shut down gracefully, the same way as on the shutdown signal.
+func WithAdminServer(srv *http.Server) Option {
+	return func(o *options) {
+		o.adminServers = append(o.adminServers, srv)
+	}
+}
+
+// WithComponent runs a component, e.g. a consumer or a background worker, with the servers.
+// The components are started after the admin servers in the order of their dependencies, see Lifecycle,
+// and the server is started once all of them are started. They are stopped after the server is shut down.
+func WithComponent(name string, c Component, opts ...ComponentOption) Option {
+	return func(o *options) {
+		o.components = append(o.components, namedComponent{name: name, component: c, opts: opts})
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		shutdownTimeout: DefaultShutdownTimeout,
+		signals:         DefaultSignals,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
@@ -2,39 +2,300 @@
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"time"
+)
+
+// ErrForcedShutdown is returned when the shutdown signal is received again during the graceful shutdown.
+var ErrForcedShutdown = errors.New("shutdown forced")
+
+// ShutdownError is returned when a hook of the graceful shutdown fails or times out.
+// The failures of the servers and the components are returned as ComponentError.
+type ShutdownError struct {
+	Phase Phase
+	Err   error
+}
+
+func (e *ShutdownError) Error() string {
+	return fmt.Sprintf("shutdown failed in the %s phase: %v", e.Phase, e.Err)
+}
+
+func (e *ShutdownError) Unwrap() error {
+	return e.Err
+}
 
-	"golang.org/x/sync/errgroup"
+// The names of the servers run by RunHTTPServerWithGracefulShutdown.
+const (
+	componentAdminServer = "admin_server"
+	componentServer      = "server"
 )
 
-func RunHTTPServerWithGracefulShutdown(ctx context.Context, srv *http.Server) error {
-	ctx, cancel := signal.NotifyContext(ctx, os.Interrupt, os.Kill)
-	defer cancel()
+// RunHTTPServerWithGracefulShutdown runs the server until the context is canceled, a shutdown signal is received,
+// or a component fails, then drains and shuts it down gracefully, see the options.
+//
+// The servers and the components added with WithComponent are run by a Lifecycle: the admin servers are started
+// first, then the components, and the server last, and they are stopped in the reverse order.
+// A failure of any of them drains and shuts down the rest the same way as the shutdown signal.
+//
+// Receiving the signal again forces the shutdown: the servers are closed immediately,
+// and ErrForcedShutdown is returned without waiting for the components and the hooks.
+func RunHTTPServerWithGracefulShutdown(ctx context.Context, srv *http.Server, opts ...Option) error {
+	o := newOptions(opts)
+
+	shutdownCtx, forceCtx, stop := notifyShutdown(ctx, o.signals)
+	defer stop()
 
-	eg, ctx := errgroup.WithContext(ctx)
+	lifecycle, err := newServerLifecycle(srv, o)
+	if err != nil {
+		return err
+	}
 
-	eg.Go(func() error {
-		<-ctx.Done()
+	done := make(chan error, 1)
 
-		slog.Info("shutting down server...")
-		ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
-		defer cancel()
+	go func() {
+		done <- runGracefully(shutdownCtx, forceCtx, lifecycle, o)
+	}()
 
-		return srv.Shutdown(ctx)
-	})
+	select {
+	case err := <-done:
+		return err
+	case <-forceCtx.Done():
+		slog.Warn("forcing shutdown")
 
-	eg.Go(func() error {
-		slog.Info("starting server", "addr", srv.Addr)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			return err
+		return errors.Join(ErrForcedShutdown, closeServers(append(o.adminServers, srv)))
+	}
+}
+
+// newServerLifecycle adds the servers and the components to a Lifecycle.
+func newServerLifecycle(srv *http.Server, o options) (*Lifecycle, error) {
+	lifecycle := NewLifecycle()
+
+	var errs []error
+
+	add := func(name string, c Component, opts ...ComponentOption) {
+		errs = append(errs, lifecycle.Add(name, c, opts...))
+	}
+
+	// The admin servers are started first and stopped last, so the probes are served during the drain
+	for i, adminSrv := range o.adminServers {
+		name := componentAdminServer
+		if i > 0 {
+			name = fmt.Sprintf("%s_%d", componentAdminServer, i+1)
 		}
 
+		add(name, NewHTTPServerComponent(adminSrv), WithStopTimeout(o.shutdownTimeout))
+	}
+
+	components := make([]string, 0, len(o.components))
+	for _, c := range o.components {
+		add(c.name, c.component, c.opts...)
+		components = append(components, c.name)
+	}
+
+	add(componentServer, NewHTTPServerComponent(srv), WithStopTimeout(o.shutdownTimeout), DependsOn(components...))
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return lifecycle, nil
+}
+
+// runGracefully starts the components, and once the context is canceled or a component fails, it runs the phases of
+// the graceful shutdown in order: the drain, the hooks before the shutdown, the stop of the components in the reverse
+// order, and the hooks after the shutdown. The drain is skipped if the components failed to start.
+// The hooks are canceled when the shutdown is forced.
+func runGracefully(ctx, forceCtx context.Context, lifecycle *Lifecycle, o options) error {
+	startErr := lifecycle.Start(ctx)
+	if startErr == nil {
+		select {
+		case <-ctx.Done():
+		case <-lifecycle.Failed():
+		}
+
+		drain(forceCtx, o)
+	}
+
+	slog.Info("shutting down server...", "timeout", o.shutdownTimeout)
+
+	beforeErr := runShutdownHooks(forceCtx, PhaseBeforeShutdown, o.beforeShutdown, o.shutdownTimeout)
+	stopErr := lifecycle.Stop()
+	afterErr := runShutdownHooks(forceCtx, PhaseAfterShutdown, o.afterShutdown, o.shutdownTimeout)
+
+	return errors.Join(startErr, beforeErr, stopErr, afterErr)
+}
+
+// closeServers closes the servers immediately, with their active connections.
+func closeServers(servers []*http.Server) error {
+	var errs []error
+	for _, srv := range servers {
+		errs = append(errs, srv.Close())
+	}
+
+	return errors.Join(errs...)
+}
+
+// notifyShutdown returns a context canceled when the parent is canceled or a signal is received,
+// and a context canceled when a signal is received again, to force the shutdown.
+func notifyShutdown(ctx context.Context, signals []os.Signal) (context.Context, context.Context, func()) {
+	shutdownCtx, cancelShutdown := context.WithCancel(ctx)
+	forceCtx, cancelForce := context.WithCancel(context.Background())
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+
+	stopped := make(chan struct{})
+
+	go func() {
+		select {
+		case sig := <-ch:
+			slog.Info("shutting down gracefully, send the signal again to force the shutdown", "signal", sig.String())
+			cancelShutdown()
+		case <-shutdownCtx.Done():
+		case <-stopped:
+			return
+		}
+
+		select {
+		case sig := <-ch:
+			slog.Warn("received the signal again", "signal", sig.String())
+			cancelForce()
+		case <-stopped:
+		}
+	}()
+
+	return shutdownCtx, forceCtx, func() {
+		signal.Stop(ch)
+		close(stopped)
+		cancelShutdown()
+		cancelForce()
+	}
+}
+
+// runShutdownHooks calls the hooks of the phase in order, within the shutdown timeout.
+func runShutdownHooks(ctx context.Context, phase Phase, hooks []ShutdownHook, timeout time.Duration) error {
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var errs []error
+	for _, hook := range hooks {
+		errs = append(errs, runShutdownPhase(ctx, phase, timeout, hook))
+	}
+
+	return errors.Join(errs...)
+}
+
+// runShutdownPhase calls fn, and returns a ShutdownError if it fails, or does not return before the context is canceled.
+func runShutdownPhase(ctx context.Context, phase Phase, timeout time.Duration, fn func(ctx context.Context) error) error {
+	err := callWithContext(ctx, fn)
+	if err == nil {
 		return nil
-	})
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		err = fmt.Errorf("timed out after %s: %w", timeout, err)
+	}
+
+	slog.Error("shutdown phase failed", "phase", phase, "error", err)
+
+	return &ShutdownError{Phase: phase, Err: err}
+}
+
+// drain notifies the drainers, and waits for the drain period while the server keeps serving the requests,
+// or until the shutdown is forced.
+func drain(ctx context.Context, o options) {
+	for _, drainer := range o.drainers {
+		drainer.Drain()
+	}
+
+	if o.drainPeriod <= 0 {
+		return
+	}
+
+	slog.Info("draining server before shutdown...", "period", o.drainPeriod)
+
+	timer := time.NewTimer(o.drainPeriod)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
+
+// HTTPServerComponent runs an HTTP server as a component of a Lifecycle.
+type HTTPServerComponent struct {
+	srv  *http.Server
+	done chan error
+}
+
+var _ interface {
+	Component
+	Waiter
+} = (*HTTPServerComponent)(nil)
+
+// NewHTTPServerComponent creates a component running the server.
+func NewHTTPServerComponent(srv *http.Server) *HTTPServerComponent {
+	return &HTTPServerComponent{srv: srv, done: make(chan error, 1)}
+}
+
+// Start starts listening on the address of the server, and serves the connections in the background.
+func (c *HTTPServerComponent) Start(ctx context.Context) error {
+	addr := c.srv.Addr
+	if addr == "" {
+		addr = ":http"
+	}
+
+	listener, err := (&net.ListenConfig{}).Listen(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("starting server", "addr", listener.Addr().String(), "tls", c.srv.TLSConfig != nil)
+
+	go func() {
+		if err := c.serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			c.done <- err
+
+			return
+		}
+
+		c.done <- nil
+	}()
+
+	return nil
+}
+
+// serve serves the connections of the listener, over TLS if the server has a TLS configuration, see NewTLSConfig.
+func (c *HTTPServerComponent) serve(listener net.Listener) error {
+	if c.srv.TLSConfig != nil {
+		return c.srv.ServeTLS(listener, "", "")
+	}
+
+	return c.srv.Serve(listener)
+}
+
+// Stop shuts the server down gracefully, waiting for the active connections until the context is canceled,
+// then closes the connections left open.
+func (c *HTTPServerComponent) Stop(ctx context.Context) error {
+	if err := c.srv.Shutdown(ctx); err != nil {
+		return errors.Join(err, c.srv.Close())
+	}
+
+	return nil
+}
 
-	return eg.Wait()
+// Wait blocks until the server stops, and returns the error if it failed.
+func (c *HTTPServerComponent) Wait() error {
+	return <-c.done
 }
@@ -0,0 +1,31 @@
+//go:build unix
+
+package infra
+
+import (
+	"context"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunHTTPServerWithGracefulShutdownSignals(t *testing.T) {
+	_, errCh := runTestServer(t, context.Background(), WithSignals(syscall.SIGUSR1))
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	require.NoError(t, waitForShutdown(t, errCh, 5*time.Second))
+}
+
+func TestRunHTTPServerWithGracefulShutdownForced(t *testing.T) {
+	_, errCh := runTestServer(t, context.Background(), WithSignals(syscall.SIGUSR1), WithDrain(time.Minute))
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+
+	// Give the first signal time to start the drain
+	time.Sleep(100 * time.Millisecond)
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	require.ErrorIs(t, waitForShutdown(t, errCh, 5*time.Second), ErrForcedShutdown)
+}
@@ -2,6 +2,7 @@
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -11,6 +12,7 @@
 	"time"
 
 	"github.com/adroit-group/gote/pkg/logger"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -86,3 +88,328 @@ func TestRunHTTPServerWithGracefulShutdown(t *testing.T) {
 		t.Error("Server did not shut down within expected time")
 	}
 }
+
+type drainerFunc func()
+
+func (f drainerFunc) Drain() { f() }
+
+func TestRunHTTPServerWithGracefulShutdownDrain(t *testing.T) {
+	logger.SetupSlog("test", io.Discard)
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	srv := &http.Server{
+		Addr: addr,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+	}
+
+	drained := make(chan struct{})
+	drainer := drainerFunc(func() { close(drained) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	serverErrCh := make(chan error, 1)
+	go func() {
+		serverErrCh <- RunHTTPServerWithGracefulShutdown(ctx, srv, WithDrain(500*time.Millisecond, drainer))
+	}()
+
+	client := &http.Client{Timeout: time.Second}
+
+	require.Eventually(t, func() bool {
+		resp, err := client.Get("http://" + addr)
+		if err != nil {
+			return false
+		}
+
+		return resp.Body.Close() == nil
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+
+	select {
+	case <-drained:
+	case <-time.After(time.Second):
+		require.Fail(t, "drainer was not notified")
+	}
+
+	// The server keeps serving the requests during the drain period
+	resp, err := client.Get("http://" + addr)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	select {
+	case err := <-serverErrCh:
+		require.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		require.Fail(t, "server did not shut down within expected time")
+	}
+
+	resp, err = client.Get("http://" + addr)
+	if err == nil {
+		require.NoError(t, resp.Body.Close())
+	}
+
+	require.Error(t, err)
+}
+
+// runTestServer runs a test server with RunHTTPServerWithGracefulShutdown until it serves the requests,
+// and returns its address, and the channel of the result.
+func runTestServer(t *testing.T, ctx context.Context, opts ...Option) (string, <-chan error) {
+	t.Helper()
+
+	logger.SetupSlog("test", io.Discard)
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	srv := &http.Server{
+		Addr: addr,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- RunHTTPServerWithGracefulShutdown(ctx, srv, opts...)
+	}()
+
+	client := &http.Client{Timeout: time.Second}
+
+	require.Eventually(t, func() bool {
+		resp, err := client.Get("http://" + addr)
+		if err != nil {
+			return false
+		}
+
+		return resp.Body.Close() == nil
+	}, time.Second, 10*time.Millisecond)
+
+	return addr, errCh
+}
+
+func waitForShutdown(t *testing.T, errCh <-chan error, timeout time.Duration) error {
+	t.Helper()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-time.After(timeout):
+		require.Fail(t, "server did not shut down within expected time")
+
+		return nil
+	}
+}
+
+func TestRunHTTPServerWithGracefulShutdownHooks(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		events []string
+	)
+
+	hook := func(event string) ShutdownHook {
+		return func(context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			events = append(events, event)
+
+			return nil
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	_, errCh := runTestServer(t, ctx,
+		WithBeforeShutdown(hook("before 1"), hook("before 2")),
+		WithAfterShutdown(hook("after")),
+	)
+
+	cancel()
+
+	require.NoError(t, waitForShutdown(t, errCh, 5*time.Second))
+	assert.Equal(t, []string{"before 1", "before 2", "after"}, events)
+}
+
+func TestRunHTTPServerWithGracefulShutdownPhaseErrors(t *testing.T) {
+	errHook := errors.New("failed to flush")
+
+	block := func(ctx context.Context) error {
+		<-ctx.Done()
+
+		return ctx.Err()
+	}
+
+	testCases := []struct {
+		name          string
+		opts          []Option
+		expectedPhase Phase
+		expectedErr   error
+	}{
+		{
+			name:          "before shutdown timeout",
+			opts:          []Option{WithBeforeShutdown(block)},
+			expectedPhase: PhaseBeforeShutdown,
+			expectedErr:   context.DeadlineExceeded,
+		},
+		{
+			name:          "after shutdown timeout",
+			opts:          []Option{WithAfterShutdown(block)},
+			expectedPhase: PhaseAfterShutdown,
+			expectedErr:   context.DeadlineExceeded,
+		},
+		{
+			name:          "after shutdown failure",
+			opts:          []Option{WithAfterShutdown(func(context.Context) error { return errHook })},
+			expectedPhase: PhaseAfterShutdown,
+			expectedErr:   errHook,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			_, errCh := runTestServer(t, ctx, append(tc.opts, WithShutdownTimeout(50*time.Millisecond))...)
+
+			cancel()
+
+			err := waitForShutdown(t, errCh, 5*time.Second)
+			require.ErrorIs(t, err, tc.expectedErr)
+
+			var shutdownErr *ShutdownError
+			require.ErrorAs(t, err, &shutdownErr)
+			assert.Equal(t, tc.expectedPhase, shutdownErr.Phase)
+		})
+	}
+}
+
+func TestRunHTTPServerWithGracefulShutdownAdminServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	adminAddr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	adminSrv := &http.Server{
+		Addr: adminAddr,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}),
+	}
+
+	drained := make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	_, errCh := runTestServer(t, ctx,
+		WithAdminServer(adminSrv),
+		WithDrain(500*time.Millisecond, drainerFunc(func() { close(drained) })),
+	)
+
+	client := &http.Client{Timeout: time.Second}
+
+	resp, err := client.Get("http://" + adminAddr)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	cancel()
+	<-drained
+
+	// The admin server keeps serving the probes during the drain period
+	resp, err = client.Get("http://" + adminAddr)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	require.NoError(t, waitForShutdown(t, errCh, 5*time.Second))
+
+	resp, err = client.Get("http://" + adminAddr)
+	if err == nil {
+		require.NoError(t, resp.Body.Close())
+	}
+
+	require.Error(t, err)
+}
+
+func TestRunHTTPServerWithGracefulShutdownAdminServerFailure(t *testing.T) {
+	logger.SetupSlog("test", io.Discard)
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	defer func() {
+		require.NoError(t, listener.Close())
+	}()
+
+	err = RunHTTPServerWithGracefulShutdown(context.Background(), &http.Server{Addr: "localhost:0"},
+		WithAdminServer(&http.Server{Addr: listener.Addr().String()}))
+	require.Error(t, err)
+}
+
+func TestRunHTTPServerWithGracefulShutdownComponents(t *testing.T) {
+	r := &recorder{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	_, errCh := runTestServer(t, ctx,
+		WithComponent("worker", r.component("worker")),
+		WithComponent("consumer", r.component("consumer"), DependsOn("worker")),
+		WithBeforeShutdown(func(context.Context) error {
+			r.record("before shutdown")
+
+			return nil
+		}),
+		WithAfterShutdown(func(context.Context) error {
+			r.record("after shutdown")
+
+			return nil
+		}),
+	)
+
+	cancel()
+
+	require.NoError(t, waitForShutdown(t, errCh, 5*time.Second))
+	assert.Equal(t, []string{
+		"start worker", "start consumer", "before shutdown", "stop consumer", "stop worker", "after shutdown",
+	}, r.events)
+}
+
+func TestRunHTTPServerWithGracefulShutdownComponentFailure(t *testing.T) {
+	r := &recorder{}
+	failed := make(chan error, 1)
+	drained := make(chan struct{})
+
+	addr, errCh := runTestServer(t, context.Background(),
+		WithComponent("consumer", failingComponent{ComponentFuncs: r.component("consumer"), failed: failed}),
+		WithDrain(500*time.Millisecond, drainerFunc(func() { close(drained) })),
+	)
+
+	failed <- errBroken
+
+	select {
+	case <-drained:
+	case <-time.After(time.Second):
+		require.Fail(t, "drainer was not notified")
+	}
+
+	// The failure drains the server the same way as the shutdown signal
+	client := &http.Client{Timeout: time.Second}
+	resp, err := client.Get("http://" + addr)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	err = waitForShutdown(t, errCh, 5*time.Second)
+	require.ErrorIs(t, err, errBroken)
+
+	var componentErr *ComponentError
+	require.ErrorAs(t, err, &componentErr)
+	assert.Equal(t, "consumer", componentErr.Name)
+	assert.Equal(t, PhaseRun, componentErr.Phase)
+	assert.Equal(t, []string{"start consumer", "stop consumer"}, r.events)
+}
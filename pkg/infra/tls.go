@@ -0,0 +1,321 @@
+package infra
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultCertificateCheckInterval is the interval of checking the certificate files for changes
+// without TLSConfig.ReloadInterval.
+const DefaultCertificateCheckInterval = 10 * time.Second
+
+// ErrInvalidTLSConfig is returned when the TLS configuration cannot be built.
+var ErrInvalidTLSConfig = errors.New("invalid TLS configuration")
+
+// tlsVersions are the supported minimum TLS versions by their names.
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// TLSConfig is the TLS configuration of a server, see NewTLSConfig.
+type TLSConfig struct {
+	// CertFile is the path of the PEM encoded certificate chain of the server.
+	CertFile string
+	// KeyFile is the path of the PEM encoded private key of the certificate.
+	KeyFile string
+	// MinVersion is the minimum accepted TLS version, "1.2" or "1.3". It is "1.2" if empty.
+	MinVersion string
+	// CipherSuites are the names of the accepted cipher suites for TLS 1.2, e.g. "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256".
+	// The secure cipher suites of crypto/tls are accepted if empty. The TLS 1.3 cipher suites are not configurable.
+	CipherSuites []string
+	// ClientCAFile is the path of the PEM encoded CA bundle used to verify the client certificates.
+	// If set, every client must present a certificate signed by one of the CAs (mutual TLS).
+	ClientCAFile string
+	// ReloadInterval is the minimum interval of checking the certificate and the client CA files for changes,
+	// DefaultCertificateCheckInterval if zero.
+	ReloadInterval time.Duration
+}
+
+// NewTLSConfig builds the server TLS configuration.
+// The certificate and the client CAs are reloaded when their files change on disk, e.g. when they are rotated by cert-manager.
+func NewTLSConfig(c TLSConfig) (*tls.Config, error) {
+	minVersion, ok := tlsVersions[c.MinVersion]
+	if c.MinVersion == "" {
+		minVersion, ok = tls.VersionTLS12, true
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported minimum TLS version %q", ErrInvalidTLSConfig, c.MinVersion)
+	}
+
+	cipherSuites, err := cipherSuiteIDs(c.CipherSuites)
+	if err != nil {
+		return nil, err
+	}
+
+	reloader, err := NewCertificateReloader(c.CertFile, c.KeyFile, c.ReloadInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{
+		MinVersion:     minVersion,
+		CipherSuites:   cipherSuites,
+		GetCertificate: reloader.GetCertificate,
+	}
+
+	if c.ClientCAFile != "" {
+		caReloader, err := NewCertPoolReloader(c.ClientCAFile, c.ReloadInterval)
+		if err != nil {
+			return nil, err
+		}
+
+		config.ClientCAs = caReloader.CertPool()
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+		config.GetConfigForClient = withClientCAs(config, caReloader)
+	}
+
+	return config, nil
+}
+
+// withClientCAs returns a tls.Config.GetConfigForClient function verifying the client certificates with the current
+// pool of the reloader. The config is only cloned when the pool is reloaded, otherwise it is used as is.
+func withClientCAs(config *tls.Config, reloader *CertPoolReloader) func(*tls.ClientHelloInfo) (*tls.Config, error) {
+	var (
+		mu      sync.Mutex
+		pool    = config.ClientCAs
+		current *tls.Config
+	)
+
+	return func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		reloaded := reloader.CertPool()
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if reloaded != pool {
+			pool = reloaded
+			current = config.Clone()
+			current.ClientCAs = reloaded
+		}
+
+		// A nil config keeps the original one
+		return current, nil
+	}
+}
+
+// cipherSuiteIDs converts the names of the cipher suites to their IDs, rejecting the insecure ones.
+func cipherSuiteIDs(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	supported := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		supported[suite.Name] = suite.ID
+	}
+
+	ids := make([]uint16, 0, len(names))
+
+	for _, name := range names {
+		id, ok := supported[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: unsupported or insecure cipher suite %q", ErrInvalidTLSConfig, name)
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// loadCertPool loads the PEM encoded certificates of the file into a new pool.
+func loadCertPool(path string) (*x509.CertPool, error) {
+	content, err := os.ReadFile(path) //nolint:gosec // reading the configured CA bundle is intended
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTLSConfig, err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(content) {
+		return nil, fmt.Errorf("%w: no certificate found in %s", ErrInvalidTLSConfig, path)
+	}
+
+	return pool, nil
+}
+
+// CertPoolReloader serves a pool of the certificates of a PEM encoded CA bundle, and reloads it when the file changes.
+// A bundle failing to load is logged, and the last good pool is served instead.
+type CertPoolReloader struct {
+	file     string
+	interval time.Duration
+
+	mu            sync.Mutex
+	pool          *x509.CertPool
+	loadedModTime time.Time
+	lastCheck     time.Time
+}
+
+// NewCertPoolReloader loads the CA bundle, and checks it for changes at most once per interval,
+// or DefaultCertificateCheckInterval if the interval is zero.
+func NewCertPoolReloader(file string, interval time.Duration) (*CertPoolReloader, error) {
+	if interval <= 0 {
+		interval = DefaultCertificateCheckInterval
+	}
+
+	r := &CertPoolReloader{file: file, interval: interval}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTLSConfig, err)
+	}
+
+	if err := r.load(info.ModTime()); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// CertPool returns the current pool. It is replaced by a new pool when the bundle is reloaded.
+func (r *CertPoolReloader) CertPool() *x509.CertPool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.lastCheck) < r.interval {
+		return r.pool
+	}
+
+	r.lastCheck = time.Now()
+
+	info, err := os.Stat(r.file)
+	if err != nil {
+		slog.Error("failed to check the CA file, using the last loaded CAs", "error", err)
+
+		return r.pool
+	}
+
+	if !info.ModTime().Equal(r.loadedModTime) {
+		if err := r.load(info.ModTime()); err != nil {
+			slog.Error("failed to reload the CAs, using the last loaded CAs", "error", err)
+		} else {
+			slog.Info("CAs reloaded", "ca_file", r.file)
+		}
+	}
+
+	return r.pool
+}
+
+// load loads the pool, and records the modification time of the file.
+func (r *CertPoolReloader) load(modTime time.Time) error {
+	pool, err := loadCertPool(r.file)
+	if err != nil {
+		return err
+	}
+
+	r.pool = pool
+	r.loadedModTime = modTime
+	r.lastCheck = time.Now()
+
+	return nil
+}
+
+// CertificateReloader serves a certificate loaded from files, and reloads it when the files change.
+// A certificate failing to load is logged, and the last good one is served instead.
+type CertificateReloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+
+	mu             sync.Mutex
+	certificate    *tls.Certificate
+	loadedModTimes [2]time.Time
+	lastCheck      time.Time
+}
+
+// NewCertificateReloader loads the certificate from the files, and checks them for changes at most once per interval,
+// or DefaultCertificateCheckInterval if the interval is zero.
+func NewCertificateReloader(certFile, keyFile string, interval time.Duration) (*CertificateReloader, error) {
+	if interval <= 0 {
+		interval = DefaultCertificateCheckInterval
+	}
+
+	r := &CertificateReloader{certFile: certFile, keyFile: keyFile, interval: interval}
+
+	modTimes, err := r.modTimes()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTLSConfig, err)
+	}
+
+	if err := r.load(modTimes); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTLSConfig, err)
+	}
+
+	return r, nil
+}
+
+// GetCertificate returns the current certificate, it can be used as tls.Config.GetCertificate.
+func (r *CertificateReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.lastCheck) < r.interval {
+		return r.certificate, nil
+	}
+
+	r.lastCheck = time.Now()
+
+	modTimes, err := r.modTimes()
+	if err != nil {
+		slog.Error("failed to check the certificate files, serving the last loaded certificate", "error", err)
+
+		return r.certificate, nil
+	}
+
+	if modTimes != r.loadedModTimes {
+		if err := r.load(modTimes); err != nil {
+			slog.Error("failed to reload the certificate, serving the last loaded certificate", "error", err)
+		} else {
+			slog.Info("certificate reloaded", "cert_file", r.certFile)
+		}
+	}
+
+	return r.certificate, nil
+}
+
+// load loads the certificate, and records the modification times of its files.
+func (r *CertificateReloader) load(modTimes [2]time.Time) error {
+	certificate, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	r.certificate = &certificate
+	r.loadedModTimes = modTimes
+	r.lastCheck = time.Now()
+
+	return nil
+}
+
+// modTimes returns the modification times of the certificate and the key files, following the symlinks.
+func (r *CertificateReloader) modTimes() ([2]time.Time, error) {
+	var modTimes [2]time.Time
+
+	for i, path := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return modTimes, err
+		}
+
+		modTimes[i] = info.ModTime()
+	}
+
+	return modTimes, nil
+}
@@ -0,0 +1,414 @@
+package infra
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCA is a locally generated certificate authority issuing the test certificates.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue issues a certificate for the common name, and returns the PEM encoded certificate and key.
+func (ca *testCA) issue(t *testing.T, commonName string, serial int64, usage x509.ExtKeyUsage) ([]byte, []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// writeServerCertificate issues a server certificate, and writes it to the cert.pem and key.pem files of the directory.
+func (ca *testCA) writeServerCertificate(t *testing.T, dir string, serial int64) (string, string) {
+	t.Helper()
+
+	certPEM, keyPEM := ca.issue(t, "server", serial, x509.ExtKeyUsageServerAuth)
+
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, certPEM, 0o600))
+	require.NoError(t, os.WriteFile(keyFile, keyPEM, 0o600))
+
+	return certFile, keyFile
+}
+
+// runTLSTestServer runs a server with the TLS configuration until the test ends, and returns its address.
+func runTLSTestServer(t *testing.T, config *tls.Config) string {
+	t.Helper()
+
+	srv := &http.Server{
+		Addr:      "127.0.0.1:0",
+		TLSConfig: config,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+	}
+
+	server := NewHTTPServerComponent(srv)
+
+	listener, err := net.Listen("tcp", srv.Addr)
+	require.NoError(t, err)
+
+	go func() {
+		_ = server.serve(listener)
+	}()
+
+	t.Cleanup(func() {
+		require.NoError(t, srv.Close())
+	})
+
+	return listener.Addr().String()
+}
+
+func tlsClient(ca *testCA, certificates ...tls.Certificate) *http.Client {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+
+	return &http.Client{
+		Timeout: time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: pool, Certificates: certificates, MinVersion: tls.VersionTLS12},
+		},
+	}
+}
+
+func get(client *http.Client, addr string) (*http.Response, error) {
+	resp, err := client.Get("https://" + addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, resp.Body.Close()
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	ca := newTestCA(t)
+	dir := t.TempDir()
+	certFile, keyFile := ca.writeServerCertificate(t, dir, 2)
+
+	caFile := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, ca.pem, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "empty.pem"), nil, 0o600))
+
+	testCases := []struct {
+		name        string
+		config      TLSConfig
+		expectedErr bool
+		check       func(t *testing.T, config *tls.Config)
+	}{
+		{
+			name:   "defaults",
+			config: TLSConfig{CertFile: certFile, KeyFile: keyFile},
+			check: func(t *testing.T, config *tls.Config) {
+				assert.Equal(t, uint16(tls.VersionTLS12), config.MinVersion)
+				assert.Nil(t, config.CipherSuites)
+				assert.Equal(t, tls.NoClientCert, config.ClientAuth)
+			},
+		},
+		{
+			name: "minimum version and cipher suites",
+			config: TLSConfig{
+				CertFile:     certFile,
+				KeyFile:      keyFile,
+				MinVersion:   "1.3",
+				CipherSuites: []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"},
+			},
+			check: func(t *testing.T, config *tls.Config) {
+				assert.Equal(t, uint16(tls.VersionTLS13), config.MinVersion)
+				assert.Equal(t, []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}, config.CipherSuites)
+			},
+		},
+		{
+			name:   "mutual TLS",
+			config: TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile},
+			check: func(t *testing.T, config *tls.Config) {
+				assert.Equal(t, tls.RequireAndVerifyClientCert, config.ClientAuth)
+				assert.NotNil(t, config.ClientCAs)
+			},
+		},
+		{
+			name:        "unsupported minimum version",
+			config:      TLSConfig{CertFile: certFile, KeyFile: keyFile, MinVersion: "1.0"},
+			expectedErr: true,
+		},
+		{
+			name:        "insecure cipher suite",
+			config:      TLSConfig{CertFile: certFile, KeyFile: keyFile, CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}},
+			expectedErr: true,
+		},
+		{
+			name:        "missing certificate",
+			config:      TLSConfig{CertFile: filepath.Join(dir, "missing.pem"), KeyFile: keyFile},
+			expectedErr: true,
+		},
+		{
+			name:        "mismatched key",
+			config:      TLSConfig{CertFile: caFile, KeyFile: keyFile},
+			expectedErr: true,
+		},
+		{
+			name:        "empty CA bundle",
+			config:      TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientCAFile: filepath.Join(dir, "empty.pem")},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config, err := NewTLSConfig(tc.config)
+
+			if tc.expectedErr {
+				require.ErrorIs(t, err, ErrInvalidTLSConfig)
+
+				return
+			}
+
+			require.NoError(t, err)
+			tc.check(t, config)
+		})
+	}
+}
+
+func TestTLSServer(t *testing.T) {
+	ca := newTestCA(t)
+	certFile, keyFile := ca.writeServerCertificate(t, t.TempDir(), 2)
+
+	config, err := NewTLSConfig(TLSConfig{CertFile: certFile, KeyFile: keyFile})
+	require.NoError(t, err)
+
+	addr := runTLSTestServer(t, config)
+
+	resp, err := get(tlsClient(ca), addr)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotNil(t, resp.TLS)
+}
+
+func TestMutualTLSServer(t *testing.T) {
+	ca := newTestCA(t)
+	dir := t.TempDir()
+	certFile, keyFile := ca.writeServerCertificate(t, dir, 2)
+
+	caFile := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, ca.pem, 0o600))
+
+	config, err := NewTLSConfig(TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile})
+	require.NoError(t, err)
+
+	addr := runTLSTestServer(t, config)
+
+	// Without a client certificate
+	_, err = get(tlsClient(ca), addr)
+	require.Error(t, err)
+
+	// With a client certificate issued by another CA
+	otherCertPEM, otherKeyPEM := newTestCA(t).issue(t, "client", 3, x509.ExtKeyUsageClientAuth)
+	otherCert, err := tls.X509KeyPair(otherCertPEM, otherKeyPEM)
+	require.NoError(t, err)
+
+	_, err = get(tlsClient(ca, otherCert), addr)
+	require.Error(t, err)
+
+	// With a client certificate issued by the trusted CA
+	clientCertPEM, clientKeyPEM := ca.issue(t, "client", 4, x509.ExtKeyUsageClientAuth)
+	clientCert, err := tls.X509KeyPair(clientCertPEM, clientKeyPEM)
+	require.NoError(t, err)
+
+	resp, err := get(tlsClient(ca, clientCert), addr)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, resp.TLS.PeerCertificates, 1)
+}
+
+func TestMutualTLSServerClientCAReload(t *testing.T) {
+	ca, otherCA := newTestCA(t), newTestCA(t)
+	dir := t.TempDir()
+	certFile, keyFile := ca.writeServerCertificate(t, dir, 2)
+
+	caFile := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, ca.pem, 0o600))
+
+	config, err := NewTLSConfig(TLSConfig{
+		CertFile:       certFile,
+		KeyFile:        keyFile,
+		ClientCAFile:   caFile,
+		ReloadInterval: 10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	addr := runTLSTestServer(t, config)
+
+	clientCertPEM, clientKeyPEM := otherCA.issue(t, "client", 3, x509.ExtKeyUsageClientAuth)
+	clientCert, err := tls.X509KeyPair(clientCertPEM, clientKeyPEM)
+	require.NoError(t, err)
+
+	_, err = get(tlsClient(ca, clientCert), addr)
+	require.Error(t, err)
+
+	// The other CA is added to the bundle
+	require.NoError(t, os.WriteFile(caFile, append(append([]byte{}, ca.pem...), otherCA.pem...), 0o600))
+
+	require.Eventually(t, func() bool {
+		_, err := get(tlsClient(ca, clientCert), addr)
+
+		return err == nil
+	}, time.Second, 20*time.Millisecond)
+}
+
+func TestCertPoolReloader(t *testing.T) {
+	ca := newTestCA(t)
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, ca.pem, 0o600))
+
+	reloader, err := NewCertPoolReloader(caFile, 10*time.Millisecond)
+	require.NoError(t, err)
+
+	pool := reloader.CertPool()
+	assert.Same(t, pool, reloader.CertPool(), "the pool is only replaced when the file changes")
+
+	// Rotated bundle
+	otherCA := newTestCA(t)
+	require.NoError(t, os.WriteFile(caFile, otherCA.pem, 0o600))
+	require.Eventually(t, func() bool { return reloader.CertPool() != pool }, time.Second, 5*time.Millisecond)
+
+	reloaded := reloader.CertPool()
+
+	// Broken bundle keeps the last good one
+	require.NoError(t, os.WriteFile(caFile, []byte("broken"), 0o600))
+	time.Sleep(20 * time.Millisecond)
+	assert.Same(t, reloaded, reloader.CertPool())
+
+	_, err = NewCertPoolReloader(filepath.Join(t.TempDir(), "missing.pem"), 0)
+	require.ErrorIs(t, err, ErrInvalidTLSConfig)
+}
+
+func TestCertificateReloader(t *testing.T) {
+	ca := newTestCA(t)
+	dir := t.TempDir()
+	certFile, keyFile := ca.writeServerCertificate(t, dir, 2)
+
+	reloader, err := NewCertificateReloader(certFile, keyFile, 10*time.Millisecond)
+	require.NoError(t, err)
+
+	serial := func() int64 {
+		certificate, err := reloader.GetCertificate(nil)
+		require.NoError(t, err)
+
+		leaf, err := x509.ParseCertificate(certificate.Certificate[0])
+		require.NoError(t, err)
+
+		return leaf.SerialNumber.Int64()
+	}
+
+	assert.Equal(t, int64(2), serial())
+
+	// Rotated certificate
+	ca.writeServerCertificate(t, dir, 3)
+	require.Eventually(t, func() bool { return serial() == 3 }, time.Second, 5*time.Millisecond)
+
+	// Broken certificate keeps the last good one
+	require.NoError(t, os.WriteFile(certFile, []byte("broken"), 0o600))
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, int64(3), serial())
+}
+
+func TestRunHTTPServerWithGracefulShutdownTLS(t *testing.T) {
+	ca := newTestCA(t)
+	certFile, keyFile := ca.writeServerCertificate(t, t.TempDir(), 2)
+
+	config, err := NewTLSConfig(TLSConfig{CertFile: certFile, KeyFile: keyFile})
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	srv := &http.Server{
+		Addr:      addr,
+		TLSConfig: config,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- RunHTTPServerWithGracefulShutdown(ctx, srv)
+	}()
+
+	client := tlsClient(ca)
+
+	require.Eventually(t, func() bool {
+		resp, err := get(client, addr)
+
+		return err == nil && resp.StatusCode == http.StatusOK
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, waitForShutdown(t, errCh, 5*time.Second))
+}
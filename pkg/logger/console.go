@@ -0,0 +1,190 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// consoleTimeFormat is the time format of the console logs, the date is left out to keep the lines short.
+const consoleTimeFormat = "15:04:05.000"
+
+// ConsoleHandler is a slog.Handler writing human-friendly lines for the local development, e.g.
+//
+//	12:04:05.123 INFO  starting server addr=:80 tls=false
+//
+// The attributes of the groups are written with the dotted keys, e.g. "cache.key=user".
+// It is not meant to be parsed, use slog.JSONHandler in production.
+type ConsoleHandler struct {
+	opts   slog.HandlerOptions
+	mu     *sync.Mutex
+	w      io.Writer
+	attrs  []byte
+	groups []string
+}
+
+var _ slog.Handler = (*ConsoleHandler)(nil)
+
+// NewConsoleHandler creates a ConsoleHandler writing to w, using the default options if opts is nil.
+func NewConsoleHandler(w io.Writer, opts *slog.HandlerOptions) *ConsoleHandler {
+	h := &ConsoleHandler{mu: &sync.Mutex{}, w: w}
+	if opts != nil {
+		h.opts = *opts
+	}
+
+	return h
+}
+
+// Enabled reports whether the level is at least the minimum level of the handler, slog.LevelInfo by default.
+func (h *ConsoleHandler) Enabled(_ context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+
+	return level >= minLevel
+}
+
+// Handle writes the record as a single line.
+func (h *ConsoleHandler) Handle(_ context.Context, r slog.Record) error {
+	buf := make([]byte, 0, 256)
+
+	if !r.Time.IsZero() {
+		buf = r.Time.AppendFormat(buf, consoleTimeFormat)
+		buf = append(buf, ' ')
+	}
+
+	buf = fmt.Appendf(buf, "%-5s %s", r.Level.String(), r.Message)
+
+	if h.opts.AddSource && r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		buf = fmt.Appendf(buf, " (%s:%d)", filepath.Base(frame.File), frame.Line)
+	}
+
+	buf = append(buf, h.attrs...)
+
+	r.Attrs(func(a slog.Attr) bool {
+		buf = h.appendAttr(buf, h.groups, a)
+
+		return true
+	})
+
+	buf = append(buf, '\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	_, err := h.w.Write(buf)
+
+	return err
+}
+
+// WithAttrs returns a handler writing the attributes with every record.
+func (h *ConsoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := h.clone()
+	for _, a := range attrs {
+		clone.attrs = h.appendAttr(clone.attrs, h.groups, a)
+	}
+
+	return clone
+}
+
+// WithGroup returns a handler writing the attributes added after it in the group.
+func (h *ConsoleHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	clone := h.clone()
+	clone.groups = append(clone.groups, name)
+
+	return clone
+}
+
+func (h *ConsoleHandler) clone() *ConsoleHandler {
+	clone := *h
+	clone.attrs = slices.Clip(h.attrs)
+	clone.groups = slices.Clip(h.groups)
+
+	return &clone
+}
+
+// appendAttr appends the attribute as " key=value", with the keys of its groups.
+func (h *ConsoleHandler) appendAttr(buf []byte, groups []string, a slog.Attr) []byte {
+	a.Value = a.Value.Resolve()
+
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			groups = append(slices.Clip(groups), a.Key)
+		}
+
+		for _, member := range a.Value.Group() {
+			buf = h.appendAttr(buf, groups, member)
+		}
+
+		return buf
+	}
+
+	if h.opts.ReplaceAttr != nil {
+		a = h.opts.ReplaceAttr(groups, a)
+		a.Value = a.Value.Resolve()
+	}
+
+	if a.Equal(slog.Attr{}) {
+		return buf
+	}
+
+	buf = append(buf, ' ')
+	for _, group := range groups {
+		buf = append(buf, group...)
+		buf = append(buf, '.')
+	}
+
+	buf = append(buf, a.Key...)
+	buf = append(buf, '=')
+
+	return appendValue(buf, a.Value)
+}
+
+func appendValue(buf []byte, v slog.Value) []byte {
+	var s string
+
+	switch v.Kind() {
+	case slog.KindTime:
+		s = v.Time().Format(time.RFC3339Nano)
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			s = err.Error()
+		} else {
+			s = v.String()
+		}
+	default:
+		s = v.String()
+	}
+
+	if needsQuoting(s) {
+		return strconv.AppendQuote(buf, s)
+	}
+
+	return append(buf, s...)
+}
+
+// needsQuoting reports whether the value must be quoted to keep the line unambiguous.
+func needsQuoting(s string) bool {
+	if s == "" {
+		return true
+	}
+
+	return strings.ContainsFunc(s, func(r rune) bool {
+		return r == '=' || r == '"' || unicode.IsSpace(r) || !unicode.IsPrint(r)
+	})
+}
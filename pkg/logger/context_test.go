@@ -0,0 +1,30 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContext(t *testing.T) {
+	ctx := context.Background()
+	assert.Same(t, slog.Default(), FromContext(ctx))
+
+	var buf bytes.Buffer
+
+	ctx = NewContext(ctx, slog.New(slog.NewJSONHandler(&buf, nil)))
+	ctx = WithAttrs(ctx, "request_id", "abc")
+	ctx = WithAttrs(ctx, slog.Int("user_id", 42))
+
+	FromContext(ctx).InfoContext(ctx, "hello")
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "abc", entry["request_id"])
+	assert.InDelta(t, 42, entry["user_id"], 0)
+}
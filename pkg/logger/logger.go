@@ -1,17 +1,89 @@
 package logger
 
 import (
+	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 )
 
-func SetupSlog(service string, output io.Writer) *slog.Logger {
-	l := slog.New(slog.NewJSONHandler(output, &slog.HandlerOptions{
-		AddSource: true,
-		Level:     slog.LevelDebug,
-	})).With("service", service)
+// Format is the format of the log lines.
+type Format string
+
+const (
+	// FormatJSON writes the records as JSON objects, see slog.JSONHandler.
+	FormatJSON Format = "json"
+	// FormatText writes the records as key=value pairs, see slog.TextHandler.
+	FormatText Format = "text"
+	// FormatConsole writes human-friendly lines for the local development, see ConsoleHandler.
+	FormatConsole Format = "console"
+)
+
+// ErrUnknownFormat is returned when the log format is not one of the supported formats.
+var ErrUnknownFormat = errors.New("unknown log format")
+
+// Options configures the logger created by New.
+type Options struct {
+	// Service is the name of the service, logged with every record.
+	Service string
+	// Level is the minimum level of the logged records, slog.LevelInfo if nil.
+	// Use a *slog.LevelVar to change it at runtime.
+	Level slog.Leveler
+	// Format is the format of the log lines, FormatJSON if empty.
+	Format Format
+	// AddSource logs the source file and line of the log calls.
+	AddSource bool
+}
+
+// New creates a logger writing to output with the options.
+func New(output io.Writer, o Options) (*slog.Logger, error) {
+	handlerOptions := &slog.HandlerOptions{
+		AddSource: o.AddSource,
+		Level:     o.Level,
+	}
+
+	var handler slog.Handler
+
+	switch o.Format {
+	case FormatJSON, "":
+		handler = slog.NewJSONHandler(output, handlerOptions)
+	case FormatText:
+		handler = slog.NewTextHandler(output, handlerOptions)
+	case FormatConsole:
+		handler = NewConsoleHandler(output, handlerOptions)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, o.Format)
+	}
+
+	l := slog.New(handler)
+	if o.Service != "" {
+		l = l.With("service", o.Service)
+	}
+
+	return l, nil
+}
+
+// Setup creates a logger with New, and sets it as the default logger of slog.
+func Setup(output io.Writer, o Options) (*slog.Logger, error) {
+	l, err := New(output, o)
+	if err != nil {
+		return nil, err
+	}
 
 	slog.SetDefault(l)
 
+	return l, nil
+}
+
+// SetupSlog sets a JSON logger logging every level with the source as the default logger of slog.
+// It is used until the configuration of the logger is loaded, see Setup.
+func SetupSlog(service string, output io.Writer) *slog.Logger {
+	l, _ := Setup(output, Options{ // the JSON format never fails
+		Service:   service,
+		Level:     slog.LevelDebug,
+		Format:    FormatJSON,
+		AddSource: true,
+	})
+
 	return l
 }
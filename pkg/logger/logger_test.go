@@ -0,0 +1,105 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		name     string
+		options  Options
+		expected *regexp.Regexp
+	}{
+		{
+			name:     "json",
+			options:  Options{Service: "test", Format: FormatJSON},
+			expected: regexp.MustCompile(`^\{"time":".+","level":"INFO","msg":"hello","service":"test","user_id":42\}\n$`),
+		},
+		{
+			name:     "default format",
+			options:  Options{},
+			expected: regexp.MustCompile(`^\{"time":".+","level":"INFO","msg":"hello","user_id":42\}\n$`),
+		},
+		{
+			name:     "text",
+			options:  Options{Service: "test", Format: FormatText},
+			expected: regexp.MustCompile(`^time=\S+ level=INFO msg=hello service=test user_id=42\n$`),
+		},
+		{
+			name:     "console",
+			options:  Options{Service: "test", Format: FormatConsole},
+			expected: regexp.MustCompile(`^\d{2}:\d{2}:\d{2}\.\d{3} INFO  hello service=test user_id=42\n$`),
+		},
+		{
+			name:     "source",
+			options:  Options{Format: FormatConsole, AddSource: true},
+			expected: regexp.MustCompile(`^\S+ INFO  hello \(logger_test\.go:\d+\) user_id=42\n$`),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			l, err := New(&buf, tc.options)
+			require.NoError(t, err)
+
+			l.Debug("debug is disabled by default")
+			l.Info("hello", "user_id", 42)
+
+			assert.Regexp(t, tc.expected, buf.String())
+		})
+	}
+
+	_, err := New(&bytes.Buffer{}, Options{Format: "xml"})
+	require.ErrorIs(t, err, ErrUnknownFormat)
+}
+
+func TestNewLevelVar(t *testing.T) {
+	var buf bytes.Buffer
+
+	level := &slog.LevelVar{}
+	level.Set(slog.LevelWarn)
+
+	l, err := New(&buf, Options{Level: level, Format: FormatConsole})
+	require.NoError(t, err)
+
+	l.Info("skipped")
+	assert.Empty(t, buf.String())
+
+	level.Set(slog.LevelDebug)
+	l.Debug("logged")
+	assert.Contains(t, buf.String(), "DEBUG logged")
+}
+
+func TestConsoleHandler(t *testing.T) {
+	var buf bytes.Buffer
+
+	l := slog.New(NewConsoleHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == "password" {
+				return slog.String(a.Key, "***")
+			}
+
+			return a
+		},
+	}))
+
+	l.With("request_id", "abc").WithGroup("db").Warn("query failed",
+		"query", "SELECT 1",
+		"password", "hunter2",
+		slog.Group("pool", "size", 10),
+		"error", errors.New("connection refused"),
+		"empty", "",
+	)
+
+	assert.Regexp(t, `^\S+ WARN  query failed request_id=abc db.query="SELECT 1" db.password=\*\*\* db.pool.size=10 `+
+		`db.error="connection refused" db.empty=""\n$`, buf.String())
+}
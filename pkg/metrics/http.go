@@ -0,0 +1,149 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// UnmatchedRoute is the route label of the requests not matching any route,
+// so the raw paths of the unknown requests do not create new series.
+const UnmatchedRoute = "unmatched"
+
+// HTTPOption configures the HTTPMetrics.
+type HTTPOption func(*httpOptions)
+
+type httpOptions struct {
+	namespace string
+	buckets   []float64
+}
+
+// WithNamespace prefixes the names of the metrics with the namespace, e.g. "myservice_http_requests_total".
+func WithNamespace(namespace string) HTTPOption {
+	return func(o *httpOptions) {
+		o.namespace = namespace
+	}
+}
+
+// WithBuckets sets the buckets of the request duration histogram in seconds, prometheus.DefBuckets by default.
+func WithBuckets(buckets ...float64) HTTPOption {
+	return func(o *httpOptions) {
+		o.buckets = buckets
+	}
+}
+
+// HTTPMetrics collects the request count, the request duration and the in-flight requests of an HTTP server.
+// The requests are labelled by their chi route pattern instead of the raw path, to keep the cardinality bounded.
+type HTTPMetrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	inFlight *prometheus.GaugeVec
+}
+
+// NewHTTPMetrics creates the HTTP metrics, and registers them with the registerer.
+// When the registerer already has the same metrics, e.g. it is shared by the handlers of several servers,
+// the registered metrics are used, so the servers report into the same series.
+func NewHTTPMetrics(registerer prometheus.Registerer, opts ...HTTPOption) (*HTTPMetrics, error) {
+	o := httpOptions{buckets: prometheus.DefBuckets}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	m := &HTTPMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: o.namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Total number of HTTP requests by route, method and status.",
+		}, []string{"route", "method", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: o.namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of the HTTP requests by route, method and status.",
+			Buckets:   o.buckets,
+		}, []string{"route", "method", "status"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: o.namespace,
+			Subsystem: "http",
+			Name:      "requests_in_flight",
+			Help:      "Number of HTTP requests being served by method.",
+		}, []string{"method"}),
+	}
+
+	var err error
+	if m.requests, err = register(registerer, m.requests); err != nil {
+		return nil, err
+	}
+
+	if m.duration, err = register(registerer, m.duration); err != nil {
+		return nil, err
+	}
+
+	if m.inFlight, err = register(registerer, m.inFlight); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// register registers the collector, or returns the already registered collector with the same descriptors.
+func register[T prometheus.Collector](registerer prometheus.Registerer, collector T) (T, error) {
+	err := registerer.Register(collector)
+	if err == nil {
+		return collector, nil
+	}
+
+	var alreadyRegistered prometheus.AlreadyRegisteredError
+	if errors.As(err, &alreadyRegistered) {
+		if existing, ok := alreadyRegistered.ExistingCollector.(T); ok {
+			return existing, nil
+		}
+	}
+
+	return collector, err
+}
+
+// Middleware records the metrics of the requests served by the next handler.
+// It must be used by a chi router, the route pattern is only known once the router matched the request.
+func (m *HTTPMetrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inFlight := m.inFlight.WithLabelValues(r.Method)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r)
+
+		status := ww.Status()
+		if status == 0 {
+			// Nothing was written, net/http responds with 200
+			status = http.StatusOK
+		}
+
+		labels := []string{routePattern(r), r.Method, strconv.Itoa(status)}
+		m.requests.WithLabelValues(labels...).Inc()
+		m.duration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+	})
+}
+
+// routePattern returns the chi route pattern matched by the request, or UnmatchedRoute.
+func routePattern(r *http.Request) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil {
+		return UnmatchedRoute
+	}
+
+	if pattern := rctx.RoutePattern(); pattern != "" {
+		return pattern
+	}
+
+	return UnmatchedRoute
+}
@@ -0,0 +1,120 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+
+	m, err := NewHTTPMetrics(registry)
+	require.NoError(t, err)
+
+	r := chi.NewRouter()
+	r.Use(m.Middleware)
+	r.Route("/api", func(r chi.Router) {
+		r.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+			assert.InDelta(t, 1, testutil.ToFloat64(m.inFlight.WithLabelValues(http.MethodGet)), 0)
+			w.WriteHeader(http.StatusOK)
+		})
+		r.Post("/users", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+		})
+		r.Get("/empty", func(w http.ResponseWriter, r *http.Request) {})
+	})
+
+	requests := []struct {
+		method string
+		path   string
+	}{
+		{method: http.MethodGet, path: "/api/users/1"},
+		{method: http.MethodGet, path: "/api/users/2"},
+		{method: http.MethodPost, path: "/api/users"},
+		{method: http.MethodGet, path: "/api/empty"},
+		{method: http.MethodGet, path: "/unknown/1"},
+		{method: http.MethodGet, path: "/unknown/2"},
+	}
+
+	for _, req := range requests {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(req.method, req.path, nil))
+	}
+
+	testCases := []struct {
+		name     string
+		labels   []string
+		expected float64
+	}{
+		{name: "route pattern", labels: []string{"/api/users/{id}", http.MethodGet, "200"}, expected: 2},
+		{name: "status", labels: []string{"/api/users", http.MethodPost, "201"}, expected: 1},
+		{name: "implicit status", labels: []string{"/api/empty", http.MethodGet, "200"}, expected: 1},
+		{name: "unmatched", labels: []string{UnmatchedRoute, http.MethodGet, "404"}, expected: 2},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.InDelta(t, tc.expected, testutil.ToFloat64(m.requests.WithLabelValues(tc.labels...)), 0)
+		})
+	}
+
+	assert.Equal(t, 4, testutil.CollectAndCount(m.requests))
+	assert.Equal(t, 4, testutil.CollectAndCount(m.duration))
+	assert.InDelta(t, 0, testutil.ToFloat64(m.inFlight.WithLabelValues(http.MethodGet)), 0)
+}
+
+func TestNewHTTPMetricsOptions(t *testing.T) {
+	registry := prometheus.NewRegistry()
+
+	m, err := NewHTTPMetrics(registry, WithNamespace("service"), WithBuckets(0.1, 1))
+	require.NoError(t, err)
+
+	m.Middleware(http.NotFoundHandler()).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	count, err := testutil.GatherAndCount(registry, "service_http_requests_total", "service_http_request_duration_seconds")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	// The registered metrics are shared
+	shared, err := NewHTTPMetrics(registry, WithNamespace("service"))
+	require.NoError(t, err)
+	assert.Same(t, m.requests, shared.requests)
+
+	shared.Middleware(http.NotFoundHandler()).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.InDelta(t, 2, testutil.ToFloat64(m.requests.WithLabelValues(UnmatchedRoute, http.MethodGet, "404")), 0)
+
+	// Different metrics with the same name cannot be registered
+	conflicting := prometheus.NewRegistry()
+	conflicting.MustRegister(prometheus.NewCounter(prometheus.CounterOpts{Name: "http_requests_total", Help: "Other."}))
+
+	_, err = NewHTTPMetrics(conflicting)
+	require.Error(t, err)
+}
+
+func TestHandler(t *testing.T) {
+	registry := NewRegistry()
+
+	m, err := NewHTTPMetrics(registry)
+	require.NoError(t, err)
+
+	r := chi.NewRouter()
+	r.Use(m.Middleware)
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) {})
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	rec := httptest.NewRecorder()
+	Handler(registry).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	body := rec.Body.String()
+	for _, name := range []string{"go_goroutines", "process_cpu_seconds_total", "http_requests_total", "http_request_duration_seconds_bucket"} {
+		assert.Contains(t, body, name)
+	}
+}